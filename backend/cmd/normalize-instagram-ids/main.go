@@ -0,0 +1,80 @@
+// Command normalize-instagram-ids rewrites the Instagram IDs in spin_logs,
+// practice_spins, players and player_lists to the canonical form new spins are
+// stored in (no "@", trimmed, lowercase), so players who typed their handle
+// differently are counted once, then rebuilds the player profiles. Handles that
+// are not valid Instagram usernames are reported and left as they are.
+//
+// Nothing else needs rewriting: vouchers, claims and gift card serials point at
+// spin logs by ID, and Redis keys no data by handle. Idempotent spin replays
+// and the live and display feed history keep the spelling they were sent with
+// until they expire or are trimmed.
+//
+//	POSTGRES_URL=postgres://... go run ./cmd/normalize-instagram-ids -dry-run
+//	POSTGRES_URL=postgres://... go run ./cmd/normalize-instagram-ids
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/repository"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/services"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report the changes without writing them")
+	flag.Parse()
+
+	godotenv.Load()
+	cfg := config.Load()
+	ctx := context.Background()
+
+	postgresRepo, err := repository.NewPostgresRepository(ctx, cfg.PostgresURL, repository.NewEventScope())
+	if err != nil {
+		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer postgresRepo.Close()
+
+	ids, err := postgresRepo.GetDistinctInstagramIDs(ctx)
+	if err != nil {
+		log.Fatalf("Failed to list Instagram IDs: %v", err)
+	}
+
+	var renamed, rows, invalid int64
+	for _, id := range ids {
+		normalized, err := services.NormalizeInstagramID(id)
+		if err != nil {
+			log.Printf("Skipping %q: %v", id, err)
+			invalid++
+			continue
+		}
+		if normalized == id {
+			continue
+		}
+
+		renamed++
+		if *dryRun {
+			log.Printf("Would rename %q to %q", id, normalized)
+			continue
+		}
+		n, err := postgresRepo.RenameInstagramID(ctx, id, normalized)
+		if err != nil {
+			log.Fatalf("Failed to rename %q: %v", id, err)
+		}
+		log.Printf("Renamed %q to %q (%d spins)", id, normalized, n)
+		rows += n
+	}
+
+	log.Printf("%d of %d Instagram IDs normalized (%d spins), %d invalid", renamed, len(ids), rows, invalid)
+
+	if *dryRun || renamed == 0 {
+		return
+	}
+	if err := postgresRepo.RebuildPlayers(ctx); err != nil {
+		log.Fatalf("Failed to rebuild player profiles: %v", err)
+	}
+	log.Println("Player profiles rebuilt")
+}
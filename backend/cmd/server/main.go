@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/ChaiyawutTar/pungdip/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/etag"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/joho/godotenv"
@@ -29,23 +31,21 @@ func main() {
 
 	ctx := context.Background()
 
+	// Redis keys and spin logs are namespaced by the current event
+	eventScope := repository.NewEventScope()
+
 	// Initialize Redis
 	log.Println("Connecting to Redis...")
-	redisRepo, err := repository.NewRedisRepository(cfg.RedisAddr, cfg)
+	redisRepo, err := repository.NewRedisRepository(cfg.RedisAddr, cfg, eventScope)
 	if err != nil {
 		log.Fatalf("Failed to connect to Redis: %v", err)
 	}
 	defer redisRepo.Close()
 	log.Println("✓ Connected to Redis")
 
-	// Initialize stocks
-	if err := redisRepo.InitializeStocks(ctx); err != nil {
-		log.Printf("Warning: Failed to initialize stocks: %v", err)
-	}
-
 	// Initialize PostgreSQL
 	log.Println("Connecting to PostgreSQL...")
-	postgresRepo, err := repository.NewPostgresRepository(ctx, cfg.PostgresURL)
+	postgresRepo, err := repository.NewPostgresRepository(ctx, cfg.PostgresURL, eventScope)
 	if err != nil {
 		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
 	}
@@ -53,11 +53,58 @@ func main() {
 	log.Println("✓ Connected to PostgreSQL")
 
 	// Initialize services
-	lotteryService := services.NewLotteryService(cfg, redisRepo, postgresRepo)
+	webhookService := services.NewWebhookService(cfg, postgresRepo)
+	liveFeedService := services.NewLiveFeedService(redisRepo, repository.AdminFeed, 0)
+	campaignService := services.NewCampaignService(cfg, redisRepo, postgresRepo, webhookService, liveFeedService)
+	lotteryService := campaignService.Default()
+	displayService, _ := campaignService.Display(config.DefaultCampaignID)
+	authService := services.NewAuthService(cfg, redisRepo, postgresRepo)
+	stationService := services.NewStationService(cfg, redisRepo, postgresRepo)
+	voucherService := services.NewVoucherService(postgresRepo)
+	playerService := services.NewPlayerService(postgresRepo)
+	claimService := services.NewClaimService(cfg, postgresRepo)
+	kioskHub := services.NewKioskHub(redisRepo, campaignService)
+	eventService := services.NewEventService(redisRepo, postgresRepo, eventScope, campaignService, liveFeedService)
+
+	// Switch to the current event before touching its stock
+	if err := eventService.Load(ctx); err != nil {
+		log.Fatalf("Failed to load current event: %v", err)
+	}
+
+	// Create missing stock counters, rebuilding them from the ledger if needed
+	if err := campaignService.SeedStocks(ctx); err != nil {
+		log.Printf("Warning: Failed to initialize stocks: %v", err)
+	}
+
+	// Send queued webhooks in the background
+	go webhookService.Run(ctx)
+
+	// Relay remote control commands to kiosks connected to this instance
+	go kioskHub.Run(ctx)
+
+	// Follow new events started on other instances
+	go eventService.Run(ctx)
+
+	// Create the bootstrap manager account
+	if err := authService.EnsureAdminUser(ctx); err != nil {
+		log.Printf("Warning: Failed to create admin user: %v", err)
+	}
 
 	// Initialize handlers
-	spinHandler := handlers.NewSpinHandler(lotteryService)
-	adminHandler := handlers.NewAdminHandler(lotteryService, cfg)
+	spinHandler := handlers.NewSpinHandler(lotteryService, cfg)
+	adminHandler := handlers.NewAdminHandler(lotteryService, authService, cfg)
+	authHandler := handlers.NewAuthHandler(authService)
+	stationHandler := handlers.NewStationHandler(stationService, authService, cfg)
+	voucherHandler := handlers.NewVoucherHandler(voucherService, authService)
+	playerHandler := handlers.NewPlayerHandler(playerService, authService)
+	claimHandler := handlers.NewClaimHandler(claimService, authService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService, authService)
+	streamHandler := handlers.NewStreamHandler(liveFeedService)
+	displayFeedHandler := handlers.NewStreamHandler(displayService.Feed())
+	displayHandler := handlers.NewDisplayHandler(displayService, authService)
+	kioskHandler := handlers.NewKioskHandler(kioskHub, stationService, authService)
+	campaignHandler := handlers.NewCampaignHandler(campaignService)
+	eventHandler := handlers.NewEventHandler(eventService, authService)
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
@@ -74,7 +121,11 @@ func main() {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
 		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders: strings.Join([]string{
+			"Origin", "Content-Type", "Accept", "Authorization", handlers.StationKeyHeader,
+			handlers.SignatureHeader, handlers.SignatureTimestampHeader, handlers.SignatureNonceHeader,
+			handlers.IdempotencyKeyHeader, handlers.LastEventIDHeader,
+		}, ", "),
 	}))
 
 	// Health check
@@ -89,17 +140,96 @@ func main() {
 	api := app.Group("/api")
 
 	// Public routes
-	api.Post("/spin", spinHandler.Spin)
+	api.Get("/wheel", spinHandler.GetWheel)
+	api.Get("/event", spinHandler.GetEvent)
+	api.Get("/prizes", etag.New(), spinHandler.GetPrizes)
+	api.Post("/spin", stationHandler.RequireStation, stationHandler.RequireSignature, spinHandler.Spin)
+	api.Get("/claims/:id/qr", claimHandler.QRCode)
+	api.Get("/display/feed", displayFeedHandler.Stream)
+	api.Get("/kiosk/ws", kioskHandler.RequireUpgrade, kioskHandler.Connect())
+	api.Get("/campaigns", campaignHandler.List)
 
 	// Admin routes
 	admin := api.Group("/admin")
+	admin.Post("/login", authHandler.Login)
+	admin.Post("/logout", authHandler.Logout)
+	admin.Post("/users", authHandler.CreateUser)
+	admin.Post("/totp/enroll", authHandler.EnrollTOTP)
+	admin.Post("/totp/confirm", authHandler.ConfirmTOTP)
 	admin.Post("/lock", adminHandler.Lock)
 	admin.Post("/unlock", adminHandler.Unlock)
 	admin.Post("/reset", adminHandler.Reset)
+	admin.Post("/pause", adminHandler.Pause)
+	admin.Post("/resume", adminHandler.Resume)
+	admin.Post("/event/override", adminHandler.SetEventOverride)
+	admin.Get("/events", eventHandler.List)
+	admin.Post("/events", eventHandler.Start)
+	admin.Post("/stocks/reconcile", adminHandler.ReconcileStocks)
+	admin.Put("/stocks/:prizeID", adminHandler.AdjustStock)
 	admin.Get("/logs", adminHandler.GetLogs)
+	admin.Get("/logs/practice", adminHandler.GetPracticeLogs)
 	admin.Get("/status", adminHandler.GetStatus)
 	admin.Get("/stats", adminHandler.GetStats)
+	admin.Get("/stream", authHandler.RequireStaffStream, streamHandler.Stream)
+	admin.Get("/display", displayHandler.GetStatus)
+	admin.Post("/display/pause", displayHandler.SetPaused)
 	admin.Get("/prizes", adminHandler.GetPrizes)
+	admin.Post("/prizes/:id/serials", adminHandler.ImportSerials)
+	admin.Post("/stations", stationHandler.Register)
+	admin.Get("/stations", stationHandler.List)
+	admin.Get("/kiosks", kioskHandler.ListOnline)
+	admin.Post("/kiosks/:id/commands", kioskHandler.SendCommand)
+	admin.Post("/stations/:id/revoke", stationHandler.Revoke)
+	admin.Post("/stations/:id/signing-secret", stationHandler.RotateSigningSecret)
+	admin.Get("/vouchers/:code", voucherHandler.Lookup)
+	admin.Post("/vouchers/:code/redeem", voucherHandler.Redeem)
+	admin.Post("/vouchers/:code/void", voucherHandler.Void)
+	admin.Post("/claims/verify", claimHandler.Verify)
+	admin.Get("/players/:id", playerHandler.Get)
+	admin.Put("/players/:id/notes", playerHandler.SetNotes)
+	admin.Get("/lists/:list", playerHandler.List)
+	admin.Post("/lists/:list", playerHandler.AddToList)
+	admin.Post("/lists/:list/import", playerHandler.ImportList)
+	admin.Delete("/lists/:list/:id", playerHandler.RemoveFromList)
+	admin.Get("/webhooks/deliveries", webhookHandler.ListDeliveries)
+	admin.Post("/webhooks/test", webhookHandler.SendTest)
+	admin.Get("/webhooks/dead-letters", webhookHandler.ListDeadLetters)
+	admin.Post("/webhooks/deliveries/:id/retry", webhookHandler.RetryDelivery)
+	admin.Post("/webhooks/subscriptions", webhookHandler.Subscribe)
+	admin.Get("/webhooks/subscriptions", webhookHandler.ListSubscriptions)
+	admin.Delete("/webhooks/subscriptions/:id", webhookHandler.Unsubscribe)
+
+	// Campaign routes. The routes above serve the default campaign; each
+	// campaign, the default included, also has its own under /campaigns/<id>.
+	for _, campaign := range campaignService.Campaigns() {
+		campaignLottery, _ := campaignService.Get(campaign.ID)
+		campaignSpin := handlers.NewSpinHandler(campaignLottery, cfg)
+		campaignAdmin := handlers.NewAdminHandler(campaignLottery, authService, cfg.ForCampaign(campaign))
+		campaignDisplay, _ := campaignService.Display(campaign.ID)
+		campaignDisplayFeed := handlers.NewStreamHandler(campaignDisplay.Feed())
+		campaignDisplayAdmin := handlers.NewDisplayHandler(campaignDisplay, authService)
+
+		public := api.Group("/campaigns/" + campaign.ID)
+		public.Get("/wheel", campaignSpin.GetWheel)
+		public.Get("/prizes", etag.New(), campaignSpin.GetPrizes)
+		public.Post("/spin", stationHandler.RequireStation, stationHandler.RequireSignature, campaignSpin.Spin)
+		public.Get("/display/feed", campaignDisplayFeed.Stream)
+
+		scoped := admin.Group("/campaigns/" + campaign.ID)
+		scoped.Post("/lock", campaignAdmin.Lock)
+		scoped.Post("/unlock", campaignAdmin.Unlock)
+		scoped.Post("/reset", campaignAdmin.Reset)
+		scoped.Post("/stocks/reconcile", campaignAdmin.ReconcileStocks)
+		scoped.Put("/stocks/:prizeID", campaignAdmin.AdjustStock)
+		scoped.Get("/logs", campaignAdmin.GetLogs)
+		scoped.Get("/logs/practice", campaignAdmin.GetPracticeLogs)
+		scoped.Get("/status", campaignAdmin.GetStatus)
+		scoped.Get("/stats", campaignAdmin.GetStats)
+		scoped.Get("/prizes", campaignAdmin.GetPrizes)
+		scoped.Post("/prizes/:id/serials", campaignAdmin.ImportSerials)
+		scoped.Get("/display", campaignDisplayAdmin.GetStatus)
+		scoped.Post("/display/pause", campaignDisplayAdmin.SetPaused)
+	}
 
 	// Graceful shutdown
 	go func() {
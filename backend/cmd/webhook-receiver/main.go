@@ -0,0 +1,57 @@
+// Command webhook-receiver is a local endpoint for trying out outgoing webhooks.
+// It prints each event, checks its signature and can fail the first requests
+// to exercise retries:
+//
+//	WEBHOOK_SECRET=dev go run ./cmd/webhook-receiver -addr :9000 -fail 2
+//	WEBHOOK_URL=http://localhost:9000 WEBHOOK_SECRET=dev go run ./cmd/server
+package main
+
+import (
+	"crypto/hmac"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync/atomic"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/services"
+)
+
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	secret := flag.String("secret", os.Getenv("WEBHOOK_SECRET"), "HMAC secret to verify signatures with")
+	fail := flag.Int64("fail", 0, "respond 500 to this many requests before accepting")
+	flag.Parse()
+
+	var received atomic.Int64
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		n := received.Add(1)
+		signature := "unsigned"
+		if *secret != "" {
+			expected := services.SignWebhook(*secret, r.Header.Get(services.WebhookTimestampHeader), body)
+			signature = "INVALID signature"
+			if hmac.Equal([]byte(expected), []byte(r.Header.Get(services.WebhookSignatureHeader))) {
+				signature = "valid signature"
+			}
+		}
+
+		log.Printf("#%d %s delivery=%s (%s)\n%s", n, r.Header.Get(services.WebhookEventHeader),
+			r.Header.Get(services.WebhookDeliveryHeader), signature, body)
+
+		if n <= *fail {
+			http.Error(w, "simulated failure", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Printf("Webhook receiver listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
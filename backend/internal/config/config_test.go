@@ -0,0 +1,56 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseEventHours(t *testing.T) {
+	loc := time.FixedZone("ICT", 7*60*60)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.January, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    []EventHours
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"single day", "2026-01-30 10:00-22:00", []EventHours{{at(30, 10, 0), at(30, 22, 0)}}, false},
+		{"date range", "2026-01-29..2026-01-31 10:00-22:00", []EventHours{
+			{at(29, 10, 0), at(29, 22, 0)},
+			{at(30, 10, 0), at(30, 22, 0)},
+			{at(31, 10, 0), at(31, 22, 0)},
+		}, false},
+		{"past midnight", "2026-01-30 18:00-02:30", []EventHours{{at(30, 18, 0), at(31, 2, 30)}}, false},
+		{"sorted with spaces", " 2026-01-31 10:00-18:00 , 2026-01-30 12:00-20:00,", []EventHours{
+			{at(30, 12, 0), at(30, 20, 0)},
+			{at(31, 10, 0), at(31, 18, 0)},
+		}, false},
+		{"range ends before it starts", "2026-01-31..2026-01-30 10:00-22:00", nil, false},
+		{"missing times", "2026-01-30", nil, true},
+		{"bad date", "2026-13-30 10:00-22:00", nil, true},
+		{"bad range end", "2026-01-30..soon 10:00-22:00", nil, true},
+		{"missing closing time", "2026-01-30 10:00", nil, true},
+		{"bad opening time", "2026-01-30 25:00-22:00", nil, true},
+		{"bad closing time", "2026-01-30 10:00-late", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEventHours(tt.value, loc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseEventHours() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseEventHours() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Opens.Equal(tt.want[i].Opens) || !got[i].Closes.Equal(tt.want[i].Closes) {
+					t.Errorf("hours[%d] = %v-%v, want %v-%v", i, got[i].Opens, got[i].Closes, tt.want[i].Opens, tt.want[i].Closes)
+				}
+			}
+		})
+	}
+}
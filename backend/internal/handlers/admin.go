@@ -1,6 +1,12 @@
 package handlers
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
 	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
 	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
 	"github.com/ChaiyawutTar/pungdip/backend/internal/services"
@@ -10,17 +16,24 @@ import (
 // AdminHandler handles admin-related endpoints
 type AdminHandler struct {
 	lottery *services.LotteryService
+	auth    *services.AuthService
 	config  *config.Config
 }
 
 // NewAdminHandler creates a new admin handler
-func NewAdminHandler(lottery *services.LotteryService, cfg *config.Config) *AdminHandler {
+func NewAdminHandler(lottery *services.LotteryService, auth *services.AuthService, cfg *config.Config) *AdminHandler {
 	return &AdminHandler{
 		lottery: lottery,
+		auth:    auth,
 		config:  cfg,
 	}
 }
 
+// authorize checks the caller has at least the given role, writing the error response if not
+func (h *AdminHandler) authorize(c *fiber.Ctx, secret, role string) (bool, error) {
+	return authorize(c, h.auth, secret, role)
+}
+
 // Lock handles POST /api/admin/lock
 func (h *AdminHandler) Lock(c *fiber.Ctx) error {
 	var req models.LockRequest
@@ -31,14 +44,6 @@ func (h *AdminHandler) Lock(c *fiber.Ctx) error {
 		})
 	}
 
-	// Verify admin secret
-	if req.Secret != h.config.AdminSecret {
-		return c.Status(fiber.StatusUnauthorized).JSON(models.APIResponse{
-			Success: false,
-			Message: "Invalid admin secret",
-		})
-	}
-
 	if req.PrizeID == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
 			Success: false,
@@ -47,35 +52,110 @@ func (h *AdminHandler) Lock(c *fiber.Ctx) error {
 	}
 
 	// Validate prize ID
-	validPrize := false
-	for _, prize := range h.config.Prizes {
+	var lockedPrize *config.Prize
+	for i, prize := range h.config.Prizes {
 		if prize.ID == req.PrizeID {
-			validPrize = true
+			lockedPrize = &h.config.Prizes[i]
 			break
 		}
 	}
-	if !validPrize {
+	if lockedPrize == nil {
 		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
 			Success: false,
 			Message: "Invalid prize ID",
 		})
 	}
 
-	if err := h.lottery.LockPrize(c.Context(), req.PrizeID); err != nil {
+	// Limited prizes can only be locked by managers
+	requiredRole := models.RoleStaff
+	if lockedPrize.IsLimited() {
+		requiredRole = models.RoleManager
+	}
+	if ok, err := h.authorize(c, req.Secret, requiredRole); !ok {
+		return err
+	}
+
+	if err := h.lottery.LockPrize(c.Context(), req.PrizeID, req.StationID, adminUsername(c)); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
 			Success: false,
 			Message: "Failed to lock prize: " + err.Error(),
 		})
 	}
 
+	message := "Prize locked for next spin: " + req.PrizeID
+	if req.StationID != "" {
+		message += " at station " + req.StationID
+	}
 	return c.JSON(models.APIResponse{
 		Success: true,
-		Message: "Prize locked for next spin: " + req.PrizeID,
+		Message: message,
 	})
 }
 
 // Unlock handles POST /api/admin/unlock
 func (h *AdminHandler) Unlock(c *fiber.Ctx) error {
+	var req models.UnlockRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if ok, err := h.authorize(c, req.Secret, models.RoleStaff); !ok {
+		return err
+	}
+
+	if err := h.lottery.UnlockPrize(c.Context(), req.StationID, adminUsername(c)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to unlock: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Prize lock removed",
+	})
+}
+
+// Pause handles POST /api/admin/pause
+func (h *AdminHandler) Pause(c *fiber.Ctx) error {
+	var req models.PauseRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if ok, err := h.authorize(c, req.Secret, models.RoleStaff); !ok {
+		return err
+	}
+
+	pause, err := h.lottery.PauseBooth(c.Context(), req.Message, req.ResumeAt, req.ResumeIn, adminUsername(c))
+	if errors.Is(err, services.ErrPauseMessage) || errors.Is(err, services.ErrPauseResumeFormat) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to pause booth: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Booth paused",
+		Data:    pause,
+	})
+}
+
+// Resume handles POST /api/admin/resume
+func (h *AdminHandler) Resume(c *fiber.Ctx) error {
 	var req struct {
 		Secret string `json:"secret"`
 	}
@@ -86,23 +166,59 @@ func (h *AdminHandler) Unlock(c *fiber.Ctx) error {
 		})
 	}
 
-	if req.Secret != h.config.AdminSecret {
-		return c.Status(fiber.StatusUnauthorized).JSON(models.APIResponse{
+	if ok, err := h.authorize(c, req.Secret, models.RoleStaff); !ok {
+		return err
+	}
+
+	if err := h.lottery.ResumeBooth(c.Context(), adminUsername(c)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to resume booth: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Booth resumed",
+	})
+}
+
+// SetEventOverride handles POST /api/admin/event/override
+func (h *AdminHandler) SetEventOverride(c *fiber.Ctx) error {
+	var req models.EventOverrideRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
 			Success: false,
-			Message: "Invalid admin secret",
+			Message: "Invalid request body",
 		})
 	}
 
-	if err := h.lottery.UnlockPrize(c.Context()); err != nil {
+	if ok, err := h.authorize(c, req.Secret, models.RoleManager); !ok {
+		return err
+	}
+
+	status, err := h.lottery.SetEventOverride(c.Context(), req.Override, adminUsername(c))
+	if errors.Is(err, services.ErrEventOverrideInvalid) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
 			Success: false,
-			Message: "Failed to unlock: " + err.Error(),
+			Message: "Failed to override opening hours: " + err.Error(),
 		})
 	}
 
+	message := "Following the event calendar"
+	if req.Override != "" {
+		message = "Booth forced " + req.Override
+	}
 	return c.JSON(models.APIResponse{
 		Success: true,
-		Message: "Prize lock removed",
+		Message: message,
+		Data:    status,
 	})
 }
 
@@ -118,14 +234,11 @@ func (h *AdminHandler) Reset(c *fiber.Ctx) error {
 		})
 	}
 
-	if req.Secret != h.config.AdminSecret {
-		return c.Status(fiber.StatusUnauthorized).JSON(models.APIResponse{
-			Success: false,
-			Message: "Invalid admin secret",
-		})
+	if ok, err := h.authorize(c, req.Secret, models.RoleManager); !ok {
+		return err
 	}
 
-	if err := h.lottery.ResetStocks(c.Context()); err != nil {
+	if err := h.lottery.ResetStocks(c.Context(), adminUsername(c)); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
 			Success: false,
 			Message: "Failed to reset stocks: " + err.Error(),
@@ -138,8 +251,154 @@ func (h *AdminHandler) Reset(c *fiber.Ctx) error {
 	})
 }
 
+// AdjustStock handles PUT /api/admin/stocks/:prizeID
+func (h *AdminHandler) AdjustStock(c *fiber.Ctx) error {
+	var req models.AdjustStockRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if ok, err := h.authorize(c, req.Secret, models.RoleManager); !ok {
+		return err
+	}
+
+	adjustment, err := h.lottery.AdjustStock(c.Context(), c.Params("prizeID"), req.Stock, req.Delta, req.Reason, adminUsername(c))
+	switch {
+	case errors.Is(err, services.ErrUnknownPrize):
+		return c.Status(fiber.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrStockNotCounted), errors.Is(err, services.ErrStockSerialPool), errors.Is(err, services.ErrStockAdjustmentFormat),
+		errors.Is(err, services.ErrStockReasonRequired), errors.Is(err, services.ErrStockNegative):
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to adjust stock: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Stock updated",
+		Data:    adjustment,
+	})
+}
+
+// ReconcileStocks handles POST /api/admin/stocks/reconcile
+func (h *AdminHandler) ReconcileStocks(c *fiber.Ctx) error {
+	var req models.ReconcileStockRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	// Reporting is read-only; rebuilding Redis needs a manager
+	requiredRole := models.RoleStaff
+	if req.Rebuild {
+		requiredRole = models.RoleManager
+	}
+	if ok, err := h.authorize(c, req.Secret, requiredRole); !ok {
+		return err
+	}
+
+	results, err := h.lottery.ReconcileStocks(c.Context(), req.Rebuild, adminUsername(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to reconcile stocks: " + err.Error(),
+		})
+	}
+
+	message := "Stocks match the ledger"
+	for _, result := range results {
+		if result.Discrepancy {
+			message = "Stock discrepancies found"
+			if req.Rebuild {
+				message = "Stock discrepancies found and Redis rebuilt"
+			}
+			break
+		}
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    results,
+	})
+}
+
+// readUpload returns an uploaded CSV, sent as a multipart "file" field or as the raw request body
+func readUpload(c *fiber.Ctx) (io.ReadCloser, error) {
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		return io.NopCloser(bytes.NewReader(c.Body())), nil
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return nil, errors.New("CSV file is required")
+	}
+	f, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	return f, nil
+}
+
+// ImportSerials handles POST /api/admin/prizes/:id/serials
+// The CSV is sent as a multipart "file" field, or as the raw request body.
+func (h *AdminHandler) ImportSerials(c *fiber.Ctx) error {
+	if ok, err := h.authorize(c, c.FormValue("secret"), models.RoleManager); !ok {
+		return err
+	}
+
+	upload, err := readUpload(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	defer upload.Close()
+
+	result, err := h.lottery.ImportSerials(c.Context(), c.Params("id"), upload, adminUsername(c))
+	switch {
+	case errors.Is(err, services.ErrNotSerialPool), errors.Is(err, services.ErrNoSerials),
+		errors.Is(err, services.ErrSerialTooLong), errors.Is(err, services.ErrSerialCSVInvalid):
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to import serials: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Serials imported",
+		Data:    result,
+	})
+}
+
 // GetLogs handles GET /api/admin/logs
+// Logs carry gift card serials that can be spent directly, so they need a staff session.
 func (h *AdminHandler) GetLogs(c *fiber.Ctx) error {
+	if ok, err := h.authorize(c, "", models.RoleStaff); !ok {
+		return err
+	}
+
 	limit := c.QueryInt("limit", 50)
 	if limit > 100 {
 		limit = 100
@@ -159,6 +418,32 @@ func (h *AdminHandler) GetLogs(c *fiber.Ctx) error {
 	})
 }
 
+// GetPracticeLogs handles GET /api/admin/logs/practice
+// Practice spins name staff accounts, so like the lists they need a staff session.
+func (h *AdminHandler) GetPracticeLogs(c *fiber.Ctx) error {
+	if ok, err := h.authorize(c, "", models.RoleStaff); !ok {
+		return err
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit > 100 {
+		limit = 100
+	}
+
+	logs, err := h.lottery.GetPracticeLogs(c.Context(), limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch practice logs: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    logs,
+	})
+}
+
 // GetStatus handles GET /api/admin/status
 func (h *AdminHandler) GetStatus(c *fiber.Ctx) error {
 	lockStatus, err := h.lottery.GetLockStatus(c.Context())
@@ -177,11 +462,20 @@ func (h *AdminHandler) GetStatus(c *fiber.Ctx) error {
 		})
 	}
 
+	pause, err := h.lottery.GetBoothPause(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to get pause status: " + err.Error(),
+		})
+	}
+
 	return c.JSON(models.APIResponse{
 		Success: true,
 		Data: map[string]interface{}{
 			"lock":   lockStatus,
 			"stocks": stocks,
+			"pause":  pause,
 		},
 	})
 }
@@ -212,6 +506,7 @@ func (h *AdminHandler) GetPrizes(c *fiber.Ctx) error {
 			"stock":        prize.Stock,
 			"probability":  prize.Probability,
 			"is_triggered": prize.IsTriggered,
+			"serial_pool":  prize.SerialPool,
 		}
 	}
 
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// adminLocalKey stores the authorized *models.AdminPrincipal on the request
+const adminLocalKey = "admin"
+
+// AuthHandler handles admin login, accounts and two-factor enrollment
+type AuthHandler struct {
+	auth *services.AuthService
+}
+
+// NewAuthHandler creates a new auth handler
+func NewAuthHandler(auth *services.AuthService) *AuthHandler {
+	return &AuthHandler{auth: auth}
+}
+
+// bearerToken extracts the session token from the Authorization header
+func bearerToken(c *fiber.Ctx) string {
+	header := c.Get(fiber.HeaderAuthorization)
+	if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// authorize authenticates an admin request by session token or shared secret and
+// checks it has at least the required role. It writes the error response if it
+// fails; handlers should return the error when ok is false. On success the
+// caller is available through adminUsername.
+func authorize(c *fiber.Ctx, auth *services.AuthService, secret, role string) (bool, error) {
+	principal, err := auth.Authenticate(c.Context(), c.IP(), bearerToken(c), secret)
+	if err != nil {
+		return false, authError(c, err)
+	}
+
+	if !models.RoleAtLeast(principal.Role, role) {
+		return false, c.Status(fiber.StatusForbidden).JSON(models.APIResponse{
+			Success: false,
+			Message: "This action requires the " + role + " role",
+		})
+	}
+
+	c.Locals(adminLocalKey, principal)
+	return true, nil
+}
+
+// RequireStaffStream is middleware for admin SSE feeds. EventSource cannot set
+// headers, so the session token may also be sent as the "token" query parameter.
+func (h *AuthHandler) RequireStaffStream(c *fiber.Ctx) error {
+	if token := c.Query("token"); token != "" && bearerToken(c) == "" {
+		c.Request().Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+
+	if ok, err := authorize(c, h.auth, "", models.RoleStaff); !ok {
+		return err
+	}
+	return c.Next()
+}
+
+// adminUsername returns who was authorized for this request, or "" if nobody
+func adminUsername(c *fiber.Ctx) string {
+	if principal, ok := c.Locals(adminLocalKey).(*models.AdminPrincipal); ok {
+		return principal.Username
+	}
+	return ""
+}
+
+// authError writes the response for an error returned by AuthService
+func authError(c *fiber.Ctx, err error) error {
+	var lockout *services.LockoutError
+	switch {
+	case errors.As(err, &lockout):
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(lockout.RetryAfter.Seconds()))))
+		return c.Status(fiber.StatusTooManyRequests).JSON(models.APIResponse{
+			Success: false,
+			Message: "Too many failed attempts, try again later",
+		})
+	case errors.Is(err, services.ErrInvalidCredentials):
+		return c.Status(fiber.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid admin credentials",
+		})
+	case errors.Is(err, services.ErrInvalidSession):
+		return c.Status(fiber.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Message: "Session expired, please log in again",
+		})
+	case errors.Is(err, services.ErrTOTPRequired):
+		return c.Status(fiber.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Message: "Two-factor code required",
+			Data:    fiber.Map{"totp_required": true},
+		})
+	case errors.Is(err, services.ErrTOTPEnrollmentRequired):
+		return c.Status(fiber.StatusForbidden).JSON(models.APIResponse{
+			Success: false,
+			Message: "Two-factor enrollment is required for this role",
+			Data:    fiber.Map{"totp_enrollment_required": true},
+		})
+	case errors.Is(err, services.ErrTOTPNotPending), errors.Is(err, services.ErrTOTPAlreadyEnabled),
+		errors.Is(err, services.ErrUserExists), errors.Is(err, services.ErrInvalidRole):
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to authenticate: " + err.Error(),
+		})
+	}
+}
+
+// Login handles POST /api/admin/login
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
+	var req models.LoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	var session *models.AdminSession
+	var err error
+	if req.Username != "" {
+		session, err = h.auth.Login(c.Context(), c.IP(), req.Username, req.Password, req.Code)
+	} else {
+		session, err = h.auth.LoginWithSecret(c.Context(), c.IP(), req.Secret)
+	}
+	if err != nil {
+		return authError(c, err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Login successful",
+		Data:    session,
+	})
+}
+
+// Logout handles POST /api/admin/logout
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	token := bearerToken(c)
+	if token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Session token is required",
+		})
+	}
+
+	if err := h.auth.Logout(c.Context(), token); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to log out: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Logged out",
+	})
+}
+
+// CreateUser handles POST /api/admin/users
+func (h *AuthHandler) CreateUser(c *fiber.Ctx) error {
+	var req models.CreateAdminUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if ok, err := authorize(c, h.auth, req.Secret, models.RoleManager); !ok {
+		return err
+	}
+
+	if req.Username == "" || len(req.Password) < 8 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Username and a password of at least 8 characters are required",
+		})
+	}
+
+	if err := h.auth.CreateUser(c.Context(), req.Username, req.Password, req.Role); err != nil {
+		return authError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.APIResponse{
+		Success: true,
+		Message: "Admin user created: " + req.Username,
+	})
+}
+
+// EnrollTOTP handles POST /api/admin/totp/enroll
+func (h *AuthHandler) EnrollTOTP(c *fiber.Ctx) error {
+	var req models.TOTPEnrollRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	enrollment, err := h.auth.BeginTOTPEnrollment(c.Context(), c.IP(), req.Username, req.Password)
+	if err != nil {
+		return authError(c, err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Scan the provisioning URI, then confirm with a code. Store the recovery codes safely.",
+		Data:    enrollment,
+	})
+}
+
+// ConfirmTOTP handles POST /api/admin/totp/confirm
+func (h *AuthHandler) ConfirmTOTP(c *fiber.Ctx) error {
+	var req models.TOTPEnrollRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if err := h.auth.ConfirmTOTPEnrollment(c.Context(), c.IP(), req.Username, req.Password, req.Code); err != nil {
+		return authError(c, err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Two-factor authentication enabled",
+	})
+}
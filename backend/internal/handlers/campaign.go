@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CampaignHandler lists the campaigns a kiosk can spin
+type CampaignHandler struct {
+	campaigns *services.CampaignService
+}
+
+// NewCampaignHandler creates a new campaign handler
+func NewCampaignHandler(campaigns *services.CampaignService) *CampaignHandler {
+	return &CampaignHandler{campaigns: campaigns}
+}
+
+// List handles GET /api/campaigns
+func (h *CampaignHandler) List(c *fiber.Ctx) error {
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    h.campaigns.List(),
+	})
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClaimHandler serves claim QR codes to winners and verifies them at the prize desk
+type ClaimHandler struct {
+	claims *services.ClaimService
+	auth   *services.AuthService
+}
+
+// NewClaimHandler creates a new claim handler
+func NewClaimHandler(claims *services.ClaimService, auth *services.AuthService) *ClaimHandler {
+	return &ClaimHandler{
+		claims: claims,
+		auth:   auth,
+	}
+}
+
+// QRCode handles GET /api/claims/:id/qr
+func (h *ClaimHandler) QRCode(c *fiber.Ctx) error {
+	png, err := h.claims.QRCode(c.Context(), c.Params("id"))
+	if errors.Is(err, services.ErrClaimNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to render claim QR code: " + err.Error(),
+		})
+	}
+
+	// The token never changes, but keep it out of shared caches
+	c.Set(fiber.HeaderCacheControl, "private, max-age=3600")
+	c.Set(fiber.HeaderContentType, "image/png")
+	return c.Send(png)
+}
+
+// Verify handles POST /api/admin/claims/verify
+func (h *ClaimHandler) Verify(c *fiber.Ctx) error {
+	var req models.VerifyClaimRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if ok, err := authorize(c, h.auth, req.Secret, models.RoleStaff); !ok {
+		return err
+	}
+
+	claim, err := h.claims.Verify(c.Context(), req.Token, adminUsername(c))
+	switch {
+	case errors.Is(err, services.ErrClaimInvalid), errors.Is(err, services.ErrClaimExpired):
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrClaimNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrClaimUsed):
+		return c.Status(fiber.StatusConflict).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+			Data:    claim,
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to verify claim: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Prize claimed: " + claim.PrizeID,
+		Data:    claim,
+	})
+}
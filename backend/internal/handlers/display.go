@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// DisplayHandler handles the admin controls for the big-screen winner display
+type DisplayHandler struct {
+	display *services.DisplayService
+	auth    *services.AuthService
+}
+
+// NewDisplayHandler creates a new display handler
+func NewDisplayHandler(display *services.DisplayService, auth *services.AuthService) *DisplayHandler {
+	return &DisplayHandler{
+		display: display,
+		auth:    auth,
+	}
+}
+
+// GetStatus handles GET /api/admin/display
+// A GET has no body, so staff authenticate with their session token.
+func (h *DisplayHandler) GetStatus(c *fiber.Ctx) error {
+	if ok, err := authorize(c, h.auth, "", models.RoleStaff); !ok {
+		return err
+	}
+
+	status, err := h.display.Status(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to get display status: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    status,
+	})
+}
+
+// SetPaused handles POST /api/admin/display/pause
+func (h *DisplayHandler) SetPaused(c *fiber.Ctx) error {
+	var req models.DisplayPauseRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if ok, err := authorize(c, h.auth, req.Secret, models.RoleStaff); !ok {
+		return err
+	}
+
+	if err := h.display.SetPaused(c.Context(), req.Paused); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to update display: " + err.Error(),
+		})
+	}
+
+	message := "Winner announcements resumed"
+	if req.Paused {
+		message = "Winner announcements paused"
+	}
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: message,
+	})
+}
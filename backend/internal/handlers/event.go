@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// EventHandler lists events and starts new ones
+type EventHandler struct {
+	events *services.EventService
+	auth   *services.AuthService
+}
+
+// NewEventHandler creates a new event handler
+func NewEventHandler(events *services.EventService, auth *services.AuthService) *EventHandler {
+	return &EventHandler{
+		events: events,
+		auth:   auth,
+	}
+}
+
+// List handles GET /api/admin/events
+// A GET has no body, so staff authenticate with their session token.
+func (h *EventHandler) List(c *fiber.Ctx) error {
+	if ok, err := authorize(c, h.auth, "", models.RoleStaff); !ok {
+		return err
+	}
+
+	events, err := h.events.List(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch events: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    events,
+	})
+}
+
+// Start handles POST /api/admin/events
+func (h *EventHandler) Start(c *fiber.Ctx) error {
+	var req models.StartEventRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if ok, err := authorize(c, h.auth, req.Secret, models.RoleManager); !ok {
+		return err
+	}
+
+	started, err := h.events.Start(c.Context(), req.ID, req.Name, adminUsername(c))
+	switch {
+	case errors.Is(err, services.ErrEventIDInvalid):
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrEventExists):
+		return c.Status(fiber.StatusConflict).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to start event: " + err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.APIResponse{
+		Success: true,
+		Message: "Started event " + started.Event.Name,
+		Data:    started,
+	})
+}
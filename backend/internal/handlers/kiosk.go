@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/services"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// kioskWriteTimeout bounds each write to a kiosk socket
+	kioskWriteTimeout = 10 * time.Second
+
+	// kioskReadTimeout drops a kiosk that has not answered pings for this long
+	kioskReadTimeout = 3 * services.KioskHeartbeat
+
+	// kioskCampaignLocalKey stores the campaign a kiosk connected with
+	kioskCampaignLocalKey = "kioskCampaign"
+)
+
+// KioskHandler handles the kiosk WebSocket and admin remote control commands
+type KioskHandler struct {
+	hub      *services.KioskHub
+	stations *services.StationService
+	auth     *services.AuthService
+}
+
+// NewKioskHandler creates a new kiosk handler
+func NewKioskHandler(hub *services.KioskHub, stations *services.StationService, auth *services.AuthService) *KioskHandler {
+	return &KioskHandler{
+		hub:      hub,
+		stations: stations,
+		auth:     auth,
+	}
+}
+
+// RequireUpgrade is middleware that accepts only WebSocket upgrades from a
+// registered kiosk. Browsers cannot set headers on a WebSocket, so the API key
+// may also be sent as the "key" query parameter. The "campaign" query parameter
+// names the campaign the kiosk spins, the default if omitted.
+func (h *KioskHandler) RequireUpgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return c.Status(fiber.StatusUpgradeRequired).JSON(models.APIResponse{
+			Success: false,
+			Message: "WebSocket upgrade required",
+		})
+	}
+
+	campaign := c.Query("campaign", config.DefaultCampaignID)
+	if !h.hub.HasCampaign(campaign) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: services.ErrKioskCampaign.Error(),
+		})
+	}
+
+	station, err := h.stations.Authenticate(c.Context(), c.Get(StationKeyHeader, c.Query("key")))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to verify station: " + err.Error(),
+		})
+	}
+	if station == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid or missing station key",
+		})
+	}
+
+	c.Locals(stationLocalKey, station)
+	c.Locals(kioskCampaignLocalKey, campaign)
+	return c.Next()
+}
+
+// kioskSocket serializes writes to a kiosk connection
+type kioskSocket struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+func (s *kioskSocket) WriteJSON(v interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conn.SetWriteDeadline(time.Now().Add(kioskWriteTimeout))
+	return s.conn.WriteJSON(v)
+}
+
+func (s *kioskSocket) Close() error {
+	return s.conn.Close()
+}
+
+// Connect handles GET /api/kiosk/ws
+// The server sends commands as JSON; the kiosk replies to each with
+// {"command_id": "...", "ok": true} or {"command_id": "...", "ok": false, "error": "..."}.
+func (h *KioskHandler) Connect() fiber.Handler {
+	return websocket.New(func(conn *websocket.Conn) {
+		station := conn.Locals(stationLocalKey).(*models.Station)
+		campaign := conn.Locals(kioskCampaignLocalKey).(string)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		socket := &kioskSocket{conn: conn}
+		release := h.hub.Connect(ctx, station.ID, campaign, socket)
+		defer release()
+
+		conn.SetReadDeadline(time.Now().Add(kioskReadTimeout))
+		conn.SetPongHandler(func(string) error {
+			h.hub.Touch(ctx, station.ID)
+			return conn.SetReadDeadline(time.Now().Add(kioskReadTimeout))
+		})
+
+		// Ping in the background; a failed ping closes the socket and ends the read loop
+		go func() {
+			ticker := time.NewTicker(services.KioskHeartbeat)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(kioskWriteTimeout)); err != nil {
+						conn.Close()
+						return
+					}
+				}
+			}
+		}()
+
+		for {
+			var ack models.KioskAck
+			if err := conn.ReadJSON(&ack); err != nil {
+				return
+			}
+			conn.SetReadDeadline(time.Now().Add(kioskReadTimeout))
+			h.hub.Touch(ctx, station.ID)
+			if ack.CommandID != "" {
+				h.hub.Acknowledge(ctx, station.ID, ack)
+			}
+		}
+	})
+}
+
+// SendCommand handles POST /api/admin/kiosks/:id/commands
+func (h *KioskHandler) SendCommand(c *fiber.Ctx) error {
+	var req models.KioskCommandRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	// Locking a limited prize of the kiosk's campaign needs a manager, as
+	// with POST /api/admin/lock
+	requiredRole := models.RoleStaff
+	if req.Type == models.KioskCommandLock {
+		prize, ok, err := h.hub.Prize(c.Context(), c.Params("id"), req.PrizeID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+				Success: false,
+				Message: "Failed to send command: " + err.Error(),
+			})
+		}
+		if ok && prize.IsLimited() {
+			requiredRole = models.RoleManager
+		}
+	}
+	if ok, err := authorize(c, h.auth, req.Secret, requiredRole); !ok {
+		return err
+	}
+
+	ack, err := h.hub.Send(c.Context(), c.Params("id"), req, adminUsername(c))
+	switch {
+	case errors.Is(err, services.ErrKioskCommandInvalid), errors.Is(err, services.ErrKioskMessageInvalid),
+		errors.Is(err, services.ErrUnknownPrize):
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrKioskOffline), errors.Is(err, services.ErrKioskCampaign):
+		return c.Status(fiber.StatusConflict).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrKioskAckTimeout):
+		return c.Status(fiber.StatusGatewayTimeout).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to send command: " + err.Error(),
+		})
+	}
+
+	if !ack.OK {
+		return c.Status(fiber.StatusBadGateway).JSON(models.APIResponse{
+			Success: false,
+			Message: "Kiosk rejected the command: " + ack.Error,
+			Data:    ack,
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Command acknowledged by " + ack.StationID,
+		Data:    ack,
+	})
+}
+
+// ListOnline handles GET /api/admin/kiosks
+// The list names the stations commands can target, so it needs a staff session.
+func (h *KioskHandler) ListOnline(c *fiber.Ctx) error {
+	if ok, err := authorize(c, h.auth, "", models.RoleStaff); !ok {
+		return err
+	}
+
+	kiosks, err := h.hub.Online(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch online kiosks: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    kiosks,
+	})
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// PlayerHandler handles player profile endpoints
+type PlayerHandler struct {
+	players *services.PlayerService
+	auth    *services.AuthService
+}
+
+// NewPlayerHandler creates a new player handler
+func NewPlayerHandler(players *services.PlayerService, auth *services.AuthService) *PlayerHandler {
+	return &PlayerHandler{
+		players: players,
+		auth:    auth,
+	}
+}
+
+// playerError maps player errors to responses
+func playerError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrInstagramIDTooLong), errors.Is(err, services.ErrInstagramIDInvalid):
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid Instagram handle",
+			Data:    models.FieldError{Field: "instagram_id", Error: err.Error()},
+		})
+	case errors.Is(err, services.ErrPlayerNotesTooLong):
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+			Data:    models.FieldError{Field: "notes", Error: err.Error()},
+		})
+	case errors.Is(err, services.ErrListReasonTooLong):
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+			Data:    models.FieldError{Field: "reason", Error: err.Error()},
+		})
+	case errors.Is(err, services.ErrListEntryRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+			Data:    models.FieldError{Field: "instagram_id", Error: err.Error()},
+		})
+	case errors.Is(err, services.ErrListCSVInvalid), errors.Is(err, services.ErrListCSVEmpty):
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrListInvalid), errors.Is(err, services.ErrListNotListed),
+		errors.Is(err, services.ErrPlayerNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to process player: " + err.Error(),
+		})
+	}
+}
+
+// Get handles GET /api/admin/players/:id
+// A GET has no body, so staff authenticate with their session token.
+func (h *PlayerHandler) Get(c *fiber.Ctx) error {
+	if ok, err := authorize(c, h.auth, "", models.RoleStaff); !ok {
+		return err
+	}
+
+	profile, err := h.players.Get(c.Context(), c.Params("id"))
+	if err != nil {
+		return playerError(c, err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    profile,
+	})
+}
+
+// SetNotes handles PUT /api/admin/players/:id/notes
+func (h *PlayerHandler) SetNotes(c *fiber.Ctx) error {
+	var req models.PlayerNotesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if ok, err := authorize(c, h.auth, req.Secret, models.RoleStaff); !ok {
+		return err
+	}
+
+	if err := h.players.SetNotes(c.Context(), c.Params("id"), req.Notes); err != nil {
+		return playerError(c, err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Notes saved",
+	})
+}
+
+// List handles GET /api/admin/lists/:list
+// A GET has no body, so staff authenticate with their session token.
+func (h *PlayerHandler) List(c *fiber.Ctx) error {
+	if ok, err := authorize(c, h.auth, "", models.RoleStaff); !ok {
+		return err
+	}
+
+	players, err := h.players.List(c.Context(), c.Params("list"))
+	if err != nil {
+		return playerError(c, err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    players,
+	})
+}
+
+// AddToList handles POST /api/admin/lists/:list
+func (h *PlayerHandler) AddToList(c *fiber.Ctx) error {
+	var req models.ListPlayerRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if ok, err := authorize(c, h.auth, req.Secret, models.RoleManager); !ok {
+		return err
+	}
+
+	player, err := h.players.AddToList(c.Context(), c.Params("list"), req.InstagramID, req.Reason, adminUsername(c))
+	if err != nil {
+		return playerError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.APIResponse{
+		Success: true,
+		Message: "@" + player.InstagramID + " added to the " + player.List + " list",
+		Data:    player,
+	})
+}
+
+// RemoveFromList handles DELETE /api/admin/lists/:list/:id
+func (h *PlayerHandler) RemoveFromList(c *fiber.Ctx) error {
+	var req struct {
+		Secret string `json:"secret"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Message: "Invalid request body",
+			})
+		}
+	}
+
+	if ok, err := authorize(c, h.auth, req.Secret, models.RoleManager); !ok {
+		return err
+	}
+
+	if err := h.players.RemoveFromList(c.Context(), c.Params("list"), c.Params("id")); err != nil {
+		return playerError(c, err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Removed from the " + c.Params("list") + " list",
+	})
+}
+
+// ImportList handles POST /api/admin/lists/:list/import
+// The CSV is sent as a multipart "file" field, or as the raw request body.
+func (h *PlayerHandler) ImportList(c *fiber.Ctx) error {
+	if ok, err := authorize(c, h.auth, c.FormValue("secret"), models.RoleManager); !ok {
+		return err
+	}
+
+	upload, err := readUpload(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	defer upload.Close()
+
+	result, err := h.players.ImportList(c.Context(), c.Params("list"), upload, adminUsername(c))
+	if err != nil {
+		return playerError(c, err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Imported %d Instagram IDs into the %s list", result.Imported, result.List),
+		Data:    result,
+	})
+}
@@ -1,19 +1,36 @@
 package handlers
 
 import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
 	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
 	"github.com/ChaiyawutTar/pungdip/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
 )
 
+const (
+	// IdempotencyKeyHeader lets kiosks retry a spin without spinning twice
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// idempotentReplayedHeader marks a response replayed from a previous request
+	idempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 128
+)
+
 // SpinHandler handles spin-related endpoints
 type SpinHandler struct {
 	lottery *services.LotteryService
+	config  *config.Config
 }
 
 // NewSpinHandler creates a new spin handler
-func NewSpinHandler(lottery *services.LotteryService) *SpinHandler {
-	return &SpinHandler{lottery: lottery}
+func NewSpinHandler(lottery *services.LotteryService, cfg *config.Config) *SpinHandler {
+	return &SpinHandler{lottery: lottery, config: cfg}
 }
 
 // Spin handles POST /api/spin
@@ -26,8 +43,62 @@ func (h *SpinHandler) Spin(c *fiber.Ctx) error {
 		})
 	}
 
-	// InstagramID is optional now
-	result, err := h.lottery.Spin(c.Context(), req.InstagramID)
+	idempotencyKey := c.Get(IdempotencyKeyHeader)
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Idempotency-Key is too long",
+		})
+	}
+
+	// Only the station the key resolves to is trusted. A body station_id
+	// is rejected when keys are required and otherwise ignored, so a
+	// request cannot take another station's lock.
+	station := stationID(c)
+	if req.StationID != "" && req.StationID != station && h.config.RequireStationKey {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "station_id does not match the station key",
+		})
+	}
+
+	// InstagramID is optional now; it is normalized and validated by the lottery
+	var result *models.SpinResult
+	var err error
+	if idempotencyKey != "" {
+		var replayed bool
+		result, replayed, err = h.lottery.SpinIdempotent(c.Context(), idempotencyKey, req.InstagramID, station)
+		if replayed {
+			c.Set(idempotentReplayedHeader, "true")
+		}
+	} else {
+		result, err = h.lottery.Spin(c.Context(), req.InstagramID, station)
+	}
+	if errors.Is(err, services.ErrInstagramIDTooLong) || errors.Is(err, services.ErrInstagramIDInvalid) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid Instagram handle",
+			Data:    models.FieldError{Field: "instagram_id", Error: err.Error()},
+		})
+	}
+	if errors.Is(err, services.ErrPlayerBlocked) {
+		return c.Status(fiber.StatusForbidden).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	if errors.Is(err, services.ErrBoothPaused) {
+		return h.boothPaused(c)
+	}
+	if errors.Is(err, services.ErrEventClosed) {
+		return h.eventClosed(c)
+	}
+	if errors.Is(err, services.ErrSpinInProgress) {
+		return c.Status(fiber.StatusConflict).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
 			Success: false,
@@ -37,3 +108,75 @@ func (h *SpinHandler) Spin(c *fiber.Ctx) error {
 
 	return c.JSON(result)
 }
+
+// boothPaused writes the 503 for a spin refused while the booth is paused, with
+// the pause message and resume time for the kiosk to show
+func (h *SpinHandler) boothPaused(c *fiber.Ctx) error {
+	pause, err := h.lottery.GetBoothPause(c.Context())
+	if err != nil || pause == nil {
+		pause = &models.BoothPause{}
+	}
+	pause.PausedBy = ""
+
+	if pause.ResumeAt != nil {
+		retryAfter := int(math.Ceil(time.Until(*pause.ResumeAt).Seconds()))
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(retryAfter, 1)))
+	}
+
+	return c.Status(fiber.StatusServiceUnavailable).JSON(models.APIResponse{
+		Success: false,
+		Message: "Booth paused",
+		Data:    pause,
+	})
+}
+
+// eventClosed writes the 503 for a spin outside opening hours, with when the booth opens next
+func (h *SpinHandler) eventClosed(c *fiber.Ctx) error {
+	status, _ := h.lottery.EventStatus(c.Context())
+	if status != nil && status.OpensAt != nil {
+		retryAfter := int(math.Ceil(time.Until(*status.OpensAt).Seconds()))
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(retryAfter, 1)))
+	}
+
+	return c.Status(fiber.StatusServiceUnavailable).JSON(models.APIResponse{
+		Success: false,
+		Message: "Booth closed",
+		Data:    status,
+	})
+}
+
+// GetEvent handles GET /api/event
+func (h *SpinHandler) GetEvent(c *fiber.Ctx) error {
+	status, err := h.lottery.EventStatus(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to get event status: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    status,
+	})
+}
+
+// GetWheel handles GET /api/wheel
+func (h *SpinHandler) GetWheel(c *fiber.Ctx) error {
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    h.lottery.GetWheel(),
+	})
+}
+
+// GetPrizes handles GET /api/prizes
+func (h *SpinHandler) GetPrizes(c *fiber.Ctx) error {
+	lang := c.Query("lang", config.DefaultLanguage)
+
+	// Prize display data only changes on deploy; the ETag middleware handles revalidation
+	c.Set(fiber.HeaderCacheControl, "public, max-age=60")
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    h.lottery.GetPublicPrizes(lang),
+	})
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// StationKeyHeader carries the kiosk API key on spin requests
+	StationKeyHeader = "X-Station-Key"
+
+	// Request signing headers, see services.SigningPayload
+	SignatureHeader          = "X-Signature"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	SignatureNonceHeader     = "X-Signature-Nonce"
+
+	stationLocalKey = "station"
+)
+
+// StationHandler handles kiosk registration and API key checks
+type StationHandler struct {
+	stations *services.StationService
+	auth     *services.AuthService
+	config   *config.Config
+}
+
+// NewStationHandler creates a new station handler
+func NewStationHandler(stations *services.StationService, auth *services.AuthService, cfg *config.Config) *StationHandler {
+	return &StationHandler{
+		stations: stations,
+		auth:     auth,
+		config:   cfg,
+	}
+}
+
+// stationID returns the station resolved by RequireStation, or "" if none
+func stationID(c *fiber.Ctx) string {
+	if station, ok := c.Locals(stationLocalKey).(*models.Station); ok {
+		return station.ID
+	}
+	return ""
+}
+
+// RequireStation is middleware that rejects requests without a valid kiosk API key.
+// When REQUIRE_STATION_KEY is off, a valid key is still recorded but not required.
+func (h *StationHandler) RequireStation(c *fiber.Ctx) error {
+	station, err := h.stations.Authenticate(c.Context(), c.Get(StationKeyHeader))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to verify station: " + err.Error(),
+		})
+	}
+
+	if station == nil {
+		if h.config.RequireStationKey {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.APIResponse{
+				Success: false,
+				Message: "Invalid or missing station key",
+			})
+		}
+		return c.Next()
+	}
+
+	c.Locals(stationLocalKey, station)
+	return c.Next()
+}
+
+// RequireSignature is middleware that verifies the HMAC signature of a kiosk
+// request. It must run after RequireStation. When REQUIRE_SIGNED_SPINS is off,
+// unsigned requests are let through but signed ones are still verified.
+func (h *StationHandler) RequireSignature(c *fiber.Ctx) error {
+	station, _ := c.Locals(stationLocalKey).(*models.Station)
+	signature := c.Get(SignatureHeader)
+
+	if !h.config.RequireSignedSpins && signature == "" {
+		return c.Next()
+	}
+	if station == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Message: "Signed requests require a station key",
+		})
+	}
+
+	err := h.stations.VerifySignature(c.Context(), station, services.SignedRequest{
+		Method:    c.Method(),
+		Path:      c.Path(),
+		Body:      c.Body(),
+		Timestamp: c.Get(SignatureTimestampHeader),
+		Nonce:     c.Get(SignatureNonceHeader),
+		Signature: signature,
+	})
+	switch {
+	case errors.Is(err, services.ErrSignatureMissing), errors.Is(err, services.ErrSignatureInvalid),
+		errors.Is(err, services.ErrSignatureExpired), errors.Is(err, services.ErrNonceReused):
+		return c.Status(fiber.StatusUnauthorized).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to verify signature: " + err.Error(),
+		})
+	}
+
+	return c.Next()
+}
+
+// Register handles POST /api/admin/stations
+func (h *StationHandler) Register(c *fiber.Ctx) error {
+	var req models.RegisterStationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if ok, err := authorize(c, h.auth, req.Secret, models.RoleManager); !ok {
+		return err
+	}
+
+	registration, err := h.stations.Register(c.Context(), req.ID, req.Name)
+	if errors.Is(err, services.ErrInvalidStationID) || errors.Is(err, services.ErrStationExists) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to register station: " + err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.APIResponse{
+		Success: true,
+		Message: "Station registered. Copy the API key and signing secret now, they will not be shown again.",
+		Data:    registration,
+	})
+}
+
+// RotateSigningSecret handles POST /api/admin/stations/:id/signing-secret
+func (h *StationHandler) RotateSigningSecret(c *fiber.Ctx) error {
+	var req struct {
+		Secret string `json:"secret"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if ok, err := authorize(c, h.auth, req.Secret, models.RoleManager); !ok {
+		return err
+	}
+
+	registration, err := h.stations.RotateSigningSecret(c.Context(), c.Params("id"))
+	if errors.Is(err, services.ErrStationNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to rotate signing secret: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Signing secret rotated. Copy it now, it will not be shown again.",
+		Data:    registration,
+	})
+}
+
+// List handles GET /api/admin/stations
+// Like registering and revoking stations, this needs a manager session.
+func (h *StationHandler) List(c *fiber.Ctx) error {
+	if ok, err := authorize(c, h.auth, "", models.RoleManager); !ok {
+		return err
+	}
+
+	stations, err := h.stations.List(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch stations: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    stations,
+	})
+}
+
+// Revoke handles POST /api/admin/stations/:id/revoke
+func (h *StationHandler) Revoke(c *fiber.Ctx) error {
+	var req struct {
+		Secret string `json:"secret"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if ok, err := authorize(c, h.auth, req.Secret, models.RoleManager); !ok {
+		return err
+	}
+
+	id := c.Params("id")
+	err := h.stations.Revoke(c.Context(), id)
+	if errors.Is(err, services.ErrStationNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to revoke station: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Station key revoked: " + id,
+	})
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// LastEventIDHeader is sent by EventSource clients when they reconnect
+	LastEventIDHeader = "Last-Event-ID"
+
+	// streamKeepAlive is how often a comment is sent so proxies keep the connection open
+	streamKeepAlive = 15 * time.Second
+
+	// streamWriteTimeout bounds each write to a stream client
+	streamWriteTimeout = 10 * time.Second
+
+	// streamRetry is the reconnect delay suggested to clients, in milliseconds
+	streamRetry = 3000
+)
+
+// StreamHandler serves an event feed as server-sent events
+type StreamHandler struct {
+	live *services.LiveFeedService
+}
+
+// NewStreamHandler creates a new stream handler
+func NewStreamHandler(live *services.LiveFeedService) *StreamHandler {
+	return &StreamHandler{live: live}
+}
+
+// Stream handles GET /api/admin/stream and GET /api/display/feed
+// Reconnecting clients resume after the Last-Event-ID header (or lastEventId query).
+func (h *StreamHandler) Stream(c *fiber.Ctx) error {
+	lastEventID := c.Get(LastEventIDHeader, c.Query("lastEventId"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	backlog, events, err := h.live.Subscribe(ctx, lastEventID)
+	if err != nil {
+		cancel()
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to subscribe to feed: " + err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	// The writer runs after the handler returns, so only the connection is kept
+	conn := c.Context().Conn()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		// flush extends the write deadline past the server's WriteTimeout for each write
+		flush := func() bool {
+			conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			return w.Flush() == nil
+		}
+
+		fmt.Fprintf(w, "retry: %d\n\n", streamRetry)
+		for _, event := range backlog {
+			writeLiveEvent(w, event)
+		}
+		if !flush() {
+			return
+		}
+
+		ticker := time.NewTicker(streamKeepAlive)
+		defer ticker.Stop()
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				writeLiveEvent(w, event)
+			case <-ticker.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+			if !flush() {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+// writeLiveEvent writes one event in the text/event-stream format
+func writeLiveEvent(w *bufio.Writer, event models.LiveEvent) {
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+}
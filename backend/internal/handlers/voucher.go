@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// VoucherHandler handles staff voucher lookup and redemption
+type VoucherHandler struct {
+	vouchers *services.VoucherService
+	auth     *services.AuthService
+}
+
+// NewVoucherHandler creates a new voucher handler
+func NewVoucherHandler(vouchers *services.VoucherService, auth *services.AuthService) *VoucherHandler {
+	return &VoucherHandler{
+		vouchers: vouchers,
+		auth:     auth,
+	}
+}
+
+// voucherError writes the response for an error returned by VoucherService
+func voucherError(c *fiber.Ctx, voucher *models.Voucher, err error) error {
+	switch {
+	case errors.Is(err, services.ErrVoucherInvalid):
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrVoucherNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrVoucherRedeemed), errors.Is(err, services.ErrVoucherVoid):
+		return c.Status(fiber.StatusConflict).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+			Data:    voucher,
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to process voucher: " + err.Error(),
+		})
+	}
+}
+
+// Lookup handles GET /api/admin/vouchers/:code
+// A GET has no body, so staff authenticate with their session token.
+func (h *VoucherHandler) Lookup(c *fiber.Ctx) error {
+	if ok, err := authorize(c, h.auth, "", models.RoleStaff); !ok {
+		return err
+	}
+
+	voucher, err := h.vouchers.Lookup(c.Context(), c.Params("code"))
+	if err != nil {
+		return voucherError(c, voucher, err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    voucher,
+	})
+}
+
+// Redeem handles POST /api/admin/vouchers/:code/redeem
+func (h *VoucherHandler) Redeem(c *fiber.Ctx) error {
+	var req struct {
+		Secret string `json:"secret"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if ok, err := authorize(c, h.auth, req.Secret, models.RoleStaff); !ok {
+		return err
+	}
+
+	voucher, err := h.vouchers.Redeem(c.Context(), c.Params("code"), adminUsername(c))
+	if err != nil {
+		return voucherError(c, voucher, err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Voucher redeemed: " + voucher.PrizeName,
+		Data:    voucher,
+	})
+}
+
+// Void handles POST /api/admin/vouchers/:code/void
+func (h *VoucherHandler) Void(c *fiber.Ctx) error {
+	var req models.VoidVoucherRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if ok, err := authorize(c, h.auth, req.Secret, models.RoleManager); !ok {
+		return err
+	}
+
+	voucher, err := h.vouchers.Void(c.Context(), c.Params("code"), req.Reason)
+	if err != nil {
+		return voucherError(c, voucher, err)
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Voucher voided",
+		Data:    voucher,
+	})
+}
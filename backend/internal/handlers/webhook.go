@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// WebhookHandler handles webhook subscriptions, the delivery log and test events
+type WebhookHandler struct {
+	webhooks *services.WebhookService
+	auth     *services.AuthService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhooks *services.WebhookService, auth *services.AuthService) *WebhookHandler {
+	return &WebhookHandler{
+		webhooks: webhooks,
+		auth:     auth,
+	}
+}
+
+// ListDeliveries handles GET /api/admin/webhooks/deliveries
+// Payloads name players, so a staff session is required.
+func (h *WebhookHandler) ListDeliveries(c *fiber.Ctx) error {
+	if ok, err := authorize(c, h.auth, "", models.RoleStaff); !ok {
+		return err
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit > 100 {
+		limit = 100
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", models.DeliveryPending, models.DeliveryDelivered, models.DeliveryFailed:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Status must be pending, delivered or failed",
+		})
+	}
+
+	deliveries, err := h.webhooks.ListDeliveries(c.Context(), status, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch webhook deliveries: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    deliveries,
+	})
+}
+
+// ListDeadLetters handles GET /api/admin/webhooks/dead-letters
+// Payloads name players, so a staff session is required.
+func (h *WebhookHandler) ListDeadLetters(c *fiber.Ctx) error {
+	if ok, err := authorize(c, h.auth, "", models.RoleStaff); !ok {
+		return err
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit > 100 {
+		limit = 100
+	}
+
+	deliveries, err := h.webhooks.ListDeliveries(c.Context(), models.DeliveryFailed, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch dead letters: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    deliveries,
+	})
+}
+
+// RetryDelivery handles POST /api/admin/webhooks/deliveries/:id/retry
+func (h *WebhookHandler) RetryDelivery(c *fiber.Ctx) error {
+	var req struct {
+		Secret string `json:"secret"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if ok, err := authorize(c, h.auth, req.Secret, models.RoleManager); !ok {
+		return err
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid delivery ID",
+		})
+	}
+
+	err = h.webhooks.RetryDelivery(c.Context(), int64(id))
+	if errors.Is(err, services.ErrDeliveryNotRetryable) {
+		return c.Status(fiber.StatusConflict).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to retry delivery: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Delivery queued for retry",
+	})
+}
+
+// Subscribe handles POST /api/admin/webhooks/subscriptions
+func (h *WebhookHandler) Subscribe(c *fiber.Ctx) error {
+	var req models.CreateWebhookSubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if ok, err := authorize(c, h.auth, req.Secret, models.RoleManager); !ok {
+		return err
+	}
+
+	registration, err := h.webhooks.Subscribe(c.Context(), req.URL, req.EventTypes, req.SigningSecret)
+	if errors.Is(err, services.ErrWebhookURLInvalid) || errors.Is(err, services.ErrWebhookEventTypeInvalid) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+			Data:    fiber.Map{"event_types": models.WebhookEventTypes},
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to create subscription: " + err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.APIResponse{
+		Success: true,
+		Message: "Subscription created. Copy the signing secret now, it will not be shown again.",
+		Data:    registration,
+	})
+}
+
+// ListSubscriptions handles GET /api/admin/webhooks/subscriptions
+// Subscriber URLs may carry tokens, so only managers see them, as with Subscribe.
+func (h *WebhookHandler) ListSubscriptions(c *fiber.Ctx) error {
+	if ok, err := authorize(c, h.auth, "", models.RoleManager); !ok {
+		return err
+	}
+
+	subs, err := h.webhooks.ListSubscriptions(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to fetch subscriptions: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Data:    subs,
+	})
+}
+
+// Unsubscribe handles DELETE /api/admin/webhooks/subscriptions/:id
+func (h *WebhookHandler) Unsubscribe(c *fiber.Ctx) error {
+	var req struct {
+		Secret string `json:"secret"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+				Success: false,
+				Message: "Invalid request body",
+			})
+		}
+	}
+
+	if ok, err := authorize(c, h.auth, req.Secret, models.RoleManager); !ok {
+		return err
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid subscription ID",
+		})
+	}
+
+	err = h.webhooks.Unsubscribe(c.Context(), int64(id))
+	if errors.Is(err, services.ErrSubscriptionNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to delete subscription: " + err.Error(),
+		})
+	}
+
+	return c.JSON(models.APIResponse{
+		Success: true,
+		Message: "Subscription deleted",
+	})
+}
+
+// SendTest handles POST /api/admin/webhooks/test
+func (h *WebhookHandler) SendTest(c *fiber.Ctx) error {
+	var req struct {
+		Secret string `json:"secret"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if ok, err := authorize(c, h.auth, req.Secret, models.RoleManager); !ok {
+		return err
+	}
+
+	event, err := h.webhooks.SendTest(c.Context())
+	if errors.Is(err, services.ErrWebhookNotConfigured) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.APIResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(models.APIResponse{
+			Success: false,
+			Message: "Failed to send test webhook: " + err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(models.APIResponse{
+		Success: true,
+		Message: "Test event queued",
+		Data:    event,
+	})
+}
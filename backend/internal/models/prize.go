@@ -1,17 +1,59 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // SpinRequest represents an incoming spin request
 type SpinRequest struct {
 	InstagramID string `json:"instagram_id"`
+	StationID   string `json:"station_id"` // must match the station key; never used to pick a station
 }
 
 // SpinResult represents the result of a spin
 type SpinResult struct {
-	Result    string `json:"result"`
-	PrizeName string `json:"prize_name"`
-	IsLocked  bool   `json:"is_locked,omitempty"`
+	Result       string  `json:"result"`
+	PrizeName    string  `json:"prize_name"`
+	IsLocked     bool    `json:"is_locked,omitempty"`
+	SegmentIndex int     `json:"segment_index"` // Index into WheelLayout.Segments, -1 if the prize has no segment
+	TargetAngle  float64 `json:"target_angle"`  // Wheel position in degrees, clockwise from the top, to stop under the pointer
+	VoucherCode  string  `json:"voucher_code,omitempty"`
+	ClaimID      string  `json:"claim_id,omitempty"`    // QR image at GET /api/claims/{claim_id}/qr
+	ClaimToken   string  `json:"claim_token,omitempty"` // Signed token encoded in the QR
+	Practice     bool    `json:"practice,omitempty"`    // Staff spin: logged separately, no stock or locks used
+}
+
+// PublicPrize is the display-only view of a prize for the game screen
+type PublicPrize struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`  // In the requested language
+	Names    map[string]string `json:"names"` // All localized names
+	Icon     string            `json:"icon"`
+	Color    string            `json:"color"`
+	Segments []int             `json:"segments"` // Wheel segment indexes showing this prize, clockwise from the top
+}
+
+// WheelSegment is one slice of the wheel as drawn by the kiosk
+type WheelSegment struct {
+	Index      int     `json:"index"`
+	PrizeID    string  `json:"prize_id"`
+	Label      string  `json:"label"`
+	Color      string  `json:"color"`
+	StartAngle float64 `json:"start_angle"`
+	EndAngle   float64 `json:"end_angle"`
+}
+
+// Campaign is a wheel with its own prizes, listed for kiosks to choose from
+type Campaign struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Default bool   `json:"default,omitempty"` // Served by the routes without a campaign ID
+}
+
+// WheelLayout is the server-owned wheel, segments clockwise from the top
+type WheelLayout struct {
+	Segments []WheelSegment `json:"segments"`
 }
 
 // SpinLog represents a logged spin transaction
@@ -21,19 +63,260 @@ type SpinLog struct {
 	PrizeWon    string    `json:"prize_won"`
 	PrizeName   string    `json:"prize_name"`
 	WasLocked   bool      `json:"was_locked"`
+	StationID   string    `json:"station_id,omitempty"`
 	Timestamp   time.Time `json:"timestamp"`
+
+	VoucherCode    string `json:"voucher_code,omitempty"`
+	VoucherStatus  string `json:"voucher_status,omitempty"`
+	GiftCardSerial string `json:"gift_card_serial,omitempty"`
+	CampaignID     string `json:"campaign_id,omitempty"`
+	EventID        string `json:"event_id,omitempty"`
+}
+
+// Voucher statuses
+const (
+	VoucherIssued   = "issued"
+	VoucherRedeemed = "redeemed"
+	VoucherVoid     = "void"
+)
+
+// Player is the profile of everyone who spun with an Instagram ID, across all
+// events. Wins counts each prize won, by prize ID; losing spins are not wins.
+type Player struct {
+	InstagramID string         `json:"instagram_id"`
+	FirstSeenAt time.Time      `json:"first_seen_at"`
+	LastSeenAt  time.Time      `json:"last_seen_at"`
+	TotalSpins  int            `json:"total_spins"`
+	Wins        map[string]int `json:"wins"`
+	Notes       string         `json:"notes"`
+}
+
+// Instagram ID lists
+const (
+	ListBlocked = "blocked" // refused by Spin
+	ListStaff   = "staff"   // spin in practice mode
+)
+
+// ListedPlayer is an Instagram ID on the blocked or staff list. An ID is on
+// at most one list.
+type ListedPlayer struct {
+	InstagramID string    `json:"instagram_id"`
+	List        string    `json:"list"`
+	Reason      string    `json:"reason,omitempty"`
+	AddedBy     string    `json:"added_by,omitempty"`
+	AddedAt     time.Time `json:"added_at"`
+}
+
+// ListPlayerRequest adds an Instagram ID to a list
+type ListPlayerRequest struct {
+	Secret      string `json:"secret"`
+	InstagramID string `json:"instagram_id"`
+	Reason      string `json:"reason"`
+}
+
+// ListImportResult reports a CSV import into a list. Invalid holds the
+// handles that were skipped because they are not valid Instagram IDs.
+type ListImportResult struct {
+	List     string   `json:"list"`
+	Imported int      `json:"imported"`
+	Invalid  []string `json:"invalid,omitempty"`
+}
+
+// PlayerProfile is a player with their most recent spins
+type PlayerProfile struct {
+	Player
+	History []SpinLog `json:"history"`
+}
+
+// PlayerNotesRequest replaces the staff notes on a player
+type PlayerNotesRequest struct {
+	Secret string `json:"secret"`
+	Notes  string `json:"notes"`
+}
+
+// Voucher is a one-time code proving a winning spin
+type Voucher struct {
+	ID         int64      `json:"id"`
+	Code       string     `json:"code"`
+	SpinLogID  int64      `json:"spin_log_id"`
+	PrizeID    string     `json:"prize_id"`
+	PrizeName  string     `json:"prize_name"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RedeemedAt *time.Time `json:"redeemed_at,omitempty"`
+	RedeemedBy string     `json:"redeemed_by,omitempty"`
+	VoidedAt   *time.Time `json:"voided_at,omitempty"`
+	VoidReason string     `json:"void_reason,omitempty"`
+}
+
+// VoidVoucherRequest represents a request to void a voucher
+type VoidVoucherRequest struct {
+	Secret string `json:"secret"`
+	Reason string `json:"reason"`
+}
+
+// Claim tracks pickup of a winning spin's prize via a signed QR token
+type Claim struct {
+	ID        string     `json:"id"`
+	SpinLogID int64      `json:"spin_log_id"`
+	PrizeID   string     `json:"prize_id"`
+	IssuedAt  time.Time  `json:"issued_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	ClaimedAt *time.Time `json:"claimed_at,omitempty"`
+	ClaimedBy string     `json:"claimed_by,omitempty"`
+
+	GiftCardSerial string `json:"gift_card_serial,omitempty"` // Card to hand over, for serial pool prizes
+}
+
+// SerialImportResult reports the outcome of a gift card serial CSV upload
+type SerialImportResult struct {
+	PrizeID    string `json:"prize_id"`
+	Imported   int    `json:"imported"`
+	Duplicates int    `json:"duplicates"`
+	Available  int    `json:"available"`
+}
+
+// VerifyClaimRequest represents a staff scan of a claim QR code
+type VerifyClaimRequest struct {
+	Secret string `json:"secret"`
+	Token  string `json:"token"`
+}
+
+// AuditEntry represents a security-relevant admin event
+type AuditEntry struct {
+	ID        int64     `json:"id"`
+	Action    string    `json:"action"`
+	IPAddress string    `json:"ip_address"`
+	Detail    string    `json:"detail"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Admin roles, in increasing order of privilege
+const (
+	RoleStaff   = "staff"   // Can lock unlimited prizes and view logs
+	RoleManager = "manager" // Can also lock limited prizes and reset stock
+)
+
+var roleRank = map[string]int{RoleStaff: 1, RoleManager: 2}
+
+// IsValidRole reports whether role is a known admin role
+func IsValidRole(role string) bool {
+	return roleRank[role] > 0
+}
+
+// RoleAtLeast reports whether role grants at least the privileges of required
+func RoleAtLeast(role, required string) bool {
+	return IsValidRole(role) && roleRank[role] >= roleRank[required]
+}
+
+// AdminUser represents an admin panel account
+type AdminUser struct {
+	ID           int64     `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	Role         string    `json:"role"`
+	TOTPSecret   string    `json:"-"`
+	TOTPEnabled  bool      `json:"totp_enabled"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AdminPrincipal identifies who is performing an admin action
+type AdminPrincipal struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// AdminSession is returned after a successful user login
+type AdminSession struct {
+	Token     string    `json:"token"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LoginRequest represents an admin login attempt, either with the shared
+// secret or with a username, password and (for privileged roles) a TOTP or recovery code
+type LoginRequest struct {
+	Secret   string `json:"secret"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// CreateAdminUserRequest represents a request to add an admin account
+type CreateAdminUserRequest struct {
+	Secret   string `json:"secret"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+// TOTPEnrollRequest represents a TOTP enrollment or confirmation request
+type TOTPEnrollRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// TOTPEnrollment is returned when a user starts TOTP enrollment
+type TOTPEnrollment struct {
+	Secret          string   `json:"secret"`
+	ProvisioningURI string   `json:"provisioning_uri"`
+	RecoveryCodes   []string `json:"recovery_codes"`
+}
+
+// Station represents a registered kiosk allowed to call /api/spin
+type Station struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	KeyPrefix     string     `json:"key_prefix"`
+	SigningSecret string     `json:"-"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastSeenAt    *time.Time `json:"last_seen_at,omitempty"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+}
+
+// RegisterStationRequest represents a request to register a kiosk
+type RegisterStationRequest struct {
+	Secret string `json:"secret"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+}
+
+// StationRegistration is returned once when a kiosk is registered.
+// The API key and signing secret are never shown again.
+type StationRegistration struct {
+	Station       Station `json:"station"`
+	APIKey        string  `json:"api_key,omitempty"`
+	SigningSecret string  `json:"signing_secret"`
 }
 
 // LockRequest represents an admin lock request
+// An empty StationID sets the global lock, used by stations without their own
 type LockRequest struct {
-	PrizeID string `json:"prize_id"`
-	Secret  string `json:"secret"`
+	PrizeID   string `json:"prize_id"`
+	StationID string `json:"station_id"`
+	Secret    string `json:"secret"`
 }
 
-// LockStatus represents the current lock status
+// UnlockRequest represents an admin unlock request for a station, or the global lock
+type UnlockRequest struct {
+	StationID string `json:"station_id"`
+	Secret    string `json:"secret"`
+}
+
+// LockStatus represents the current lock status. IsLocked and LockedPrizeID
+// describe the global lock.
 type LockStatus struct {
-	IsLocked      bool   `json:"is_locked"`
-	LockedPrizeID string `json:"locked_prize_id,omitempty"`
+	IsLocked      bool          `json:"is_locked"`
+	LockedPrizeID string        `json:"locked_prize_id,omitempty"`
+	StationLocks  []StationLock `json:"station_locks"`
+}
+
+// StationLock is a prize locked for the next spin at one station
+type StationLock struct {
+	StationID string `json:"station_id"`
+	PrizeID   string `json:"prize_id"`
 }
 
 // StockStatus represents stock information
@@ -42,9 +325,375 @@ type StockStatus struct {
 	Stock   int    `json:"stock"`
 }
 
+// Stock ledger entry kinds. Seed, reset and adjust entries set the stock
+// outright; award and restore entries follow spins. Import entries add
+// serials to a serial pool.
+const (
+	StockLedgerSeed    = "seed"
+	StockLedgerAward   = "award"
+	StockLedgerRestore = "restore"
+	StockLedgerAdjust  = "adjust"
+	StockLedgerReset   = "reset"
+	StockLedgerImport  = "import"
+)
+
+// StockLedgerEntry records one change to a prize's stock
+type StockLedgerEntry struct {
+	ID         int64     `json:"id"`
+	PrizeID    string    `json:"prize_id"`
+	Kind       string    `json:"kind"`
+	Change     int       `json:"change"`
+	StockAfter int       `json:"stock_after"`
+	Reason     string    `json:"reason,omitempty"`
+	Actor      string    `json:"actor,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// AdjustStockRequest sets a prize's stock (Stock) or changes it by Delta; exactly one is required
+type AdjustStockRequest struct {
+	Secret string `json:"secret"`
+	Stock  *int   `json:"stock"`
+	Delta  *int   `json:"delta"`
+	Reason string `json:"reason"`
+}
+
+// StockAdjustment reports the result of a stock adjustment
+type StockAdjustment struct {
+	PrizeID string `json:"prize_id"`
+	Before  int    `json:"before"`
+	After   int    `json:"after"`
+}
+
+// ReconcileStockRequest asks for a stock reconciliation, optionally rebuilding Redis
+type ReconcileStockRequest struct {
+	Secret  string `json:"secret"`
+	Rebuild bool   `json:"rebuild"`
+}
+
+// StockReconciliation compares a prize's Redis stock with what the ledger and
+// spin logs say it should be. Expected is the last seed, reset or adjust entry
+// minus the spins logged for the prize since.
+type StockReconciliation struct {
+	PrizeID     string     `json:"prize_id"`
+	RedisStock  *int       `json:"redis_stock"` // nil if the counter is missing
+	LedgerStock int        `json:"ledger_stock"`
+	Expected    int        `json:"expected"`
+	AnchorKind  string     `json:"anchor_kind,omitempty"`
+	AnchorAt    *time.Time `json:"anchor_at,omitempty"`
+	SpinsSince  int        `json:"spins_since_anchor"`
+	AwardsSince int        `json:"ledger_awards_since_anchor"`
+	SerialPool  bool       `json:"serial_pool,omitempty"`
+	PoolTotal   int        `json:"pool_total,omitempty"`     // Serials ever imported, for serial pools
+	PoolLeft    int        `json:"pool_available,omitempty"` // Unassigned serials, for serial pools
+	Discrepancy bool       `json:"discrepancy"`
+	Rebuilt     bool       `json:"rebuilt"`
+}
+
+// Webhook event types
+const (
+	EventSpinCompleted = "spin.completed"
+	EventLockSet       = "lock.set"
+	EventLockConsumed  = "lock.consumed"
+	EventStockReset    = "stock.reset"
+	EventStockLow      = "stock.low"
+	EventStockSoldOut  = "stock.sold_out"
+	EventLockFallback  = "lock.fallback"
+	EventWebhookTest   = "webhook.test"
+)
+
+// WebhookEventTypes lists the event types a subscription can receive
+var WebhookEventTypes = []string{
+	EventSpinCompleted, EventLockSet, EventLockConsumed, EventStockReset,
+	EventStockLow, EventStockSoldOut, EventLockFallback,
+}
+
+// IsStockAlert reports whether an event type is sent to the WEBHOOK_URL receiver
+func IsStockAlert(eventType string) bool {
+	return eventType == EventStockLow || eventType == EventStockSoldOut || eventType == EventLockFallback
+}
+
+// Live feed event types, in addition to the webhook event types
+const (
+	EventBoothPaused     = "booth.paused"
+	EventBoothResumed    = "booth.resumed"
+	EventHoursOverridden = "event.override"
+	EventLockCleared     = "lock.cleared"
+	EventStockChanged    = "stock.changed"
+	EventStarted         = "event.started"
+)
+
+// LiveEvent is one event on the admin live feed. ID is the Redis stream ID,
+// sent as the SSE id for Last-Event-ID resume.
+type LiveEvent struct {
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// StockChange is the data of a stock.changed event
+type StockChange struct {
+	PrizeID string `json:"prize_id"`
+	Kind    string `json:"kind"` // Stock ledger kind, or "serial" for a serial pool win
+	Change  int    `json:"change"`
+	Stock   int    `json:"stock"`
+}
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookEvent is the JSON body of an outgoing webhook
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// SpinEvent is the data of a spin.completed event
+type SpinEvent struct {
+	InstagramID string `json:"instagram_id"`
+	PrizeID     string `json:"prize_id"`
+	PrizeName   string `json:"prize_name"`
+	WasLocked   bool   `json:"was_locked"`
+	StationID   string `json:"station_id,omitempty"`
+}
+
+// LockEvent is the data of lock.set and lock.consumed events
+type LockEvent struct {
+	PrizeID   string `json:"prize_id"`
+	PrizeName string `json:"prize_name"`
+	Actor     string `json:"actor,omitempty"`      // Admin who set the lock
+	StationID string `json:"station_id,omitempty"` // Kiosk whose spin used the lock
+}
+
+// StockResetEvent is the data of a stock.reset event
+type StockResetEvent struct {
+	Actor  string         `json:"actor,omitempty"`
+	Stocks map[string]int `json:"stocks"`
+}
+
+// StockAlert is the data of stock.low, stock.sold_out and lock.fallback events
+type StockAlert struct {
+	PrizeID   string `json:"prize_id"`
+	PrizeName string `json:"prize_name"`
+	Stock     int    `json:"stock"`
+	Threshold int    `json:"threshold,omitempty"`
+	StationID string `json:"station_id,omitempty"`
+}
+
+// WebhookSubscription sends the chosen event types to a URL
+type WebhookSubscription struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CreateWebhookSubscriptionRequest registers a subscription. A signing secret
+// is generated if none is given.
+type CreateWebhookSubscriptionRequest struct {
+	Secret        string   `json:"secret"`
+	URL           string   `json:"url"`
+	EventTypes    []string `json:"event_types"`
+	SigningSecret string   `json:"signing_secret"`
+}
+
+// WebhookSubscriptionRegistration is returned once when a subscription is created
+type WebhookSubscriptionRegistration struct {
+	Subscription  WebhookSubscription `json:"subscription"`
+	SigningSecret string              `json:"signing_secret"`
+}
+
+// WebhookDelivery is one event queued for, or sent to, a webhook receiver
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID *int64          `json:"subscription_id,omitempty"` // nil for the WEBHOOK_URL receiver
+	Secret         string          `json:"-"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	URL            string          `json:"url"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// BoothPause is set while spinning is paused for restocking or maintenance
+type BoothPause struct {
+	Message  string     `json:"message,omitempty"`
+	ResumeAt *time.Time `json:"resume_at,omitempty"` // nil until an admin resumes
+	PausedBy string     `json:"paused_by,omitempty"`
+	PausedAt time.Time  `json:"paused_at"`
+}
+
+// PauseRequest pauses spinning. ResumeAt or ResumeIn (a duration such as
+// "15m") sets when spinning resumes by itself.
+type PauseRequest struct {
+	Secret   string     `json:"secret"`
+	Message  string     `json:"message"`
+	ResumeAt *time.Time `json:"resume_at"`
+	ResumeIn string     `json:"resume_in"`
+}
+
+// Event hours overrides
+const (
+	EventOverrideOpen   = "open"   // accept spins outside opening hours
+	EventOverrideClosed = "closed" // refuse spins during opening hours
+)
+
+// EventHours is one opening period of the booth
+type EventHours struct {
+	Opens  time.Time `json:"opens"`
+	Closes time.Time `json:"closes"`
+}
+
+// EventStatus tells the frontend whether the booth is open and when that changes.
+// OpensAt is nil when closed with no opening left; ClosesAt is nil when open with
+// no closing time.
+type EventStatus struct {
+	Open     bool         `json:"open"`
+	Override string       `json:"override,omitempty"`
+	OpensAt  *time.Time   `json:"opens_at,omitempty"`
+	ClosesAt *time.Time   `json:"closes_at,omitempty"`
+	Timezone string       `json:"timezone"`
+	Hours    []EventHours `json:"hours"`
+}
+
+// EventOverrideRequest forces the booth open or closed, or clears the override
+type EventOverrideRequest struct {
+	Secret   string `json:"secret"`
+	Override string `json:"override"` // "open", "closed" or "" to follow the calendar
+}
+
+// EventOverrideEvent is the data of an event.override event
+type EventOverrideEvent struct {
+	Override string `json:"override"`
+	Open     bool   `json:"open"`
+	Actor    string `json:"actor,omitempty"`
+}
+
+// Event is one fair the booth runs at. Stock, locks and spin logs belong to
+// the current event; archived events keep their spin logs for reporting.
+type Event struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	StartedAt  time.Time  `json:"started_at"`
+	StartedBy  string     `json:"started_by,omitempty"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	TotalSpins int        `json:"total_spins"`
+}
+
+// StartEventRequest archives the current event and starts a new one
+type StartEventRequest struct {
+	Secret string `json:"secret"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+}
+
+// EventStartedEvent is the data of an event.started event. Archived is the
+// event it replaced, if any.
+type EventStartedEvent struct {
+	Event    Event  `json:"event"`
+	Archived *Event `json:"archived,omitempty"`
+}
+
+// BoothResumedEvent is the data of a booth.resumed event
+type BoothResumedEvent struct {
+	Actor string `json:"actor,omitempty"`
+}
+
+// Display feed event types
+const (
+	EventWinnerAnnounced = "winner.announced"
+	EventDisplayPaused   = "display.paused"
+	EventDisplayResumed  = "display.resumed"
+)
+
+// Announcement is a win shown on the big-screen display, e.g.
+// "@ch***ar won MK Duck Card". InstagramID is masked.
+type Announcement struct {
+	InstagramID string    `json:"instagram_id,omitempty"`
+	PrizeID     string    `json:"prize_id"`
+	PrizeName   string    `json:"prize_name"`
+	Icon        string    `json:"icon,omitempty"`
+	Text        string    `json:"text"`
+	WonAt       time.Time `json:"won_at"`
+}
+
+// DisplayStatus reports whether announcements are paused and which prizes are announced
+type DisplayStatus struct {
+	Paused          bool     `json:"paused"`
+	AnnouncedPrizes []string `json:"announced_prizes"`
+}
+
+// DisplayPauseRequest pauses or resumes winner announcements
+type DisplayPauseRequest struct {
+	Secret string `json:"secret"`
+	Paused bool   `json:"paused"`
+}
+
+// Kiosk remote control command types
+const (
+	KioskCommandLock    = "lock"    // lock a prize for the kiosk's next spin
+	KioskCommandPause   = "pause"   // stop the kiosk accepting spins
+	KioskCommandResume  = "resume"  // undo pause
+	KioskCommandMessage = "message" // show Message on the kiosk screen
+	KioskCommandSpin    = "spin"    // start a spin as if the button was pressed
+)
+
+// KioskCommand is pushed to a kiosk over its WebSocket
+type KioskCommand struct {
+	ID        string    `json:"id"`
+	StationID string    `json:"station_id"`
+	Type      string    `json:"type"`
+	PrizeID   string    `json:"prize_id,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	IssuedBy  string    `json:"issued_by,omitempty"`
+	IssuedAt  time.Time `json:"issued_at"`
+}
+
+// KioskCommandRequest sends a command to a kiosk
+type KioskCommandRequest struct {
+	Secret  string `json:"secret"`
+	Type    string `json:"type"`
+	PrizeID string `json:"prize_id"`
+	Message string `json:"message"`
+}
+
+// KioskAck is a kiosk's reply to a command. The kiosk sends command_id, ok
+// and error; the server fills in the rest.
+type KioskAck struct {
+	CommandID string    `json:"command_id"`
+	StationID string    `json:"station_id"`
+	OK        bool      `json:"ok"`
+	Error     string    `json:"error,omitempty"`
+	AckedAt   time.Time `json:"acked_at"`
+}
+
+// KioskPresence is a kiosk with an open WebSocket
+type KioskPresence struct {
+	StationID string    `json:"station_id"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
 // APIResponse is a generic API response
 type APIResponse struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
 }
+
+// FieldError is the data of a 400 response, naming the request field that is invalid
+type FieldError struct {
+	Field string `json:"field"`
+	Error string `json:"error"`
+}
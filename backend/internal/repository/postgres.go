@@ -2,19 +2,42 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
 	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrDuplicate is returned when an insert violates a unique constraint
+var ErrDuplicate = errors.New("duplicate key")
+
+// ErrSerialPoolEmpty is returned when a prize has no unassigned gift card serials left
+var ErrSerialPoolEmpty = errors.New("serial pool is empty")
+
+// noPrizeID is the losing result, not counted in a player's wins
+const noPrizeID = "NOTHING"
+
+// isUniqueViolation reports whether err is a PostgreSQL unique_violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
 // PostgresRepository handles PostgreSQL operations
 type PostgresRepository struct {
-	pool *pgxpool.Pool
+	pool     *pgxpool.Pool
+	events   *EventScope
+	campaign string // campaign of the spin logs and stock ledger entries read and written
 }
 
 // NewPostgresRepository creates a new PostgreSQL repository
-func NewPostgresRepository(ctx context.Context, connString string) (*PostgresRepository, error) {
+func NewPostgresRepository(ctx context.Context, connString string, events *EventScope) (*PostgresRepository, error) {
 	pool, err := pgxpool.New(ctx, connString)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
@@ -25,7 +48,7 @@ func NewPostgresRepository(ctx context.Context, connString string) (*PostgresRep
 		return nil, fmt.Errorf("failed to ping PostgreSQL: %w", err)
 	}
 
-	repo := &PostgresRepository{pool: pool}
+	repo := &PostgresRepository{pool: pool, events: events, campaign: config.DefaultCampaignID}
 
 	// Initialize schema
 	if err := repo.initSchema(ctx); err != nil {
@@ -35,6 +58,12 @@ func NewPostgresRepository(ctx context.Context, connString string) (*PostgresRep
 	return repo, nil
 }
 
+// ForCampaign returns a repository for a campaign's spin logs and stock
+// ledger, sharing this connection pool
+func (r *PostgresRepository) ForCampaign(campaignID string) *PostgresRepository {
+	return &PostgresRepository{pool: r.pool, events: r.events, campaign: campaignID}
+}
+
 // Close closes the database connection pool
 func (r *PostgresRepository) Close() {
 	r.pool.Close()
@@ -52,12 +81,196 @@ func (r *PostgresRepository) initSchema(ctx context.Context) error {
 			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 		);
 		
+		ALTER TABLE spin_logs ADD COLUMN IF NOT EXISTS station_id VARCHAR(50);
+		ALTER TABLE spin_logs ADD COLUMN IF NOT EXISTS gift_card_serial VARCHAR(100);
+		ALTER TABLE spin_logs ADD COLUMN IF NOT EXISTS campaign_id VARCHAR(50) NOT NULL DEFAULT 'main';
+		ALTER TABLE spin_logs ADD COLUMN IF NOT EXISTS event_id VARCHAR(50) NOT NULL DEFAULT 'default';
+
 		CREATE INDEX IF NOT EXISTS idx_spin_logs_instagram ON spin_logs(instagram_id);
 		CREATE INDEX IF NOT EXISTS idx_spin_logs_created_at ON spin_logs(created_at DESC);
+		CREATE INDEX IF NOT EXISTS idx_spin_logs_campaign ON spin_logs(campaign_id, created_at DESC);
+		CREATE INDEX IF NOT EXISTS idx_spin_logs_event ON spin_logs(event_id, campaign_id, created_at DESC);
+
+		CREATE TABLE IF NOT EXISTS events (
+			id VARCHAR(50) PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			started_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			started_by VARCHAR(64) NOT NULL DEFAULT '',
+			archived_at TIMESTAMP WITH TIME ZONE
+		);
+
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_events_current ON events((archived_at IS NULL)) WHERE archived_at IS NULL;
+
+		INSERT INTO events (id, name)
+		SELECT 'default', 'Default event'
+		WHERE NOT EXISTS (SELECT 1 FROM events)
+		ON CONFLICT DO NOTHING;
+
+		CREATE TABLE IF NOT EXISTS admin_audit_logs (
+			id SERIAL PRIMARY KEY,
+			action VARCHAR(50) NOT NULL,
+			ip_address VARCHAR(64) NOT NULL DEFAULT '',
+			detail TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_created_at ON admin_audit_logs(created_at DESC);
+
+		CREATE TABLE IF NOT EXISTS admin_users (
+			id SERIAL PRIMARY KEY,
+			username VARCHAR(64) NOT NULL UNIQUE,
+			password_hash VARCHAR(255) NOT NULL,
+			role VARCHAR(20) NOT NULL,
+			totp_secret VARCHAR(64) NOT NULL DEFAULT '',
+			totp_enabled BOOLEAN DEFAULT FALSE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS admin_recovery_codes (
+			id SERIAL PRIMARY KEY,
+			user_id INTEGER NOT NULL REFERENCES admin_users(id) ON DELETE CASCADE,
+			code_hash VARCHAR(64) NOT NULL,
+			used_at TIMESTAMP WITH TIME ZONE
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_admin_recovery_codes_user ON admin_recovery_codes(user_id);
+
+		CREATE TABLE IF NOT EXISTS stations (
+			id VARCHAR(50) PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			key_hash VARCHAR(64) NOT NULL UNIQUE,
+			key_prefix VARCHAR(20) NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			last_seen_at TIMESTAMP WITH TIME ZONE,
+			revoked_at TIMESTAMP WITH TIME ZONE
+		);
+
+		ALTER TABLE stations ADD COLUMN IF NOT EXISTS signing_secret VARCHAR(64) NOT NULL DEFAULT '';
+
+		CREATE TABLE IF NOT EXISTS vouchers (
+			id SERIAL PRIMARY KEY,
+			code VARCHAR(16) NOT NULL UNIQUE,
+			spin_log_id INTEGER NOT NULL REFERENCES spin_logs(id),
+			prize_id VARCHAR(50) NOT NULL,
+			prize_name VARCHAR(255) NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'issued',
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			redeemed_at TIMESTAMP WITH TIME ZONE,
+			redeemed_by VARCHAR(64) NOT NULL DEFAULT '',
+			voided_at TIMESTAMP WITH TIME ZONE,
+			void_reason TEXT NOT NULL DEFAULT ''
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_vouchers_spin_log ON vouchers(spin_log_id);
+
+		CREATE TABLE IF NOT EXISTS claims (
+			id VARCHAR(32) PRIMARY KEY,
+			spin_log_id INTEGER NOT NULL UNIQUE REFERENCES spin_logs(id),
+			prize_id VARCHAR(50) NOT NULL,
+			issued_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			claimed_at TIMESTAMP WITH TIME ZONE,
+			claimed_by VARCHAR(64) NOT NULL DEFAULT ''
+		);
+
+		CREATE TABLE IF NOT EXISTS gift_card_serials (
+			id SERIAL PRIMARY KEY,
+			prize_id VARCHAR(50) NOT NULL,
+			serial VARCHAR(100) NOT NULL UNIQUE,
+			imported_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			spin_log_id INTEGER UNIQUE REFERENCES spin_logs(id),
+			assigned_at TIMESTAMP WITH TIME ZONE
+		);
+
+		CREATE TABLE IF NOT EXISTS stock_ledger (
+			id SERIAL PRIMARY KEY,
+			prize_id VARCHAR(50) NOT NULL,
+			kind VARCHAR(20) NOT NULL,
+			change INTEGER NOT NULL,
+			stock_after INTEGER NOT NULL,
+			reason TEXT NOT NULL DEFAULT '',
+			actor VARCHAR(64) NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_stock_ledger_prize ON stock_ledger(prize_id, id);
+		ALTER TABLE stock_ledger ADD COLUMN IF NOT EXISTS campaign_id VARCHAR(50) NOT NULL DEFAULT 'main';
+		ALTER TABLE stock_ledger ADD COLUMN IF NOT EXISTS event_id VARCHAR(50) NOT NULL DEFAULT 'default';
+
+		CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id SERIAL PRIMARY KEY,
+			event_id VARCHAR(32) NOT NULL,
+			event_type VARCHAR(50) NOT NULL,
+			url TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			last_status_code INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			delivered_at TIMESTAMP WITH TIME ZONE
+		);
+
+		CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+			id SERIAL PRIMARY KEY,
+			url TEXT NOT NULL,
+			event_types TEXT[] NOT NULL,
+			secret VARCHAR(128) NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			deleted_at TIMESTAMP WITH TIME ZONE
+		);
+
+		ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS subscription_id INTEGER REFERENCES webhook_subscriptions(id);
+
+		CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+
+		CREATE INDEX IF NOT EXISTS idx_gift_card_serials_available ON gift_card_serials(prize_id, id) WHERE spin_log_id IS NULL;
+
+		CREATE TABLE IF NOT EXISTS player_lists (
+			instagram_id VARCHAR(255) PRIMARY KEY,
+			list VARCHAR(20) NOT NULL,
+			reason TEXT NOT NULL DEFAULT '',
+			added_by VARCHAR(64) NOT NULL DEFAULT '',
+			added_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+
+		CREATE TABLE IF NOT EXISTS practice_spins (
+			id SERIAL PRIMARY KEY,
+			instagram_id VARCHAR(255) NOT NULL,
+			prize_won VARCHAR(50) NOT NULL,
+			prize_name VARCHAR(255) NOT NULL,
+			station_id VARCHAR(50),
+			campaign_id VARCHAR(50) NOT NULL,
+			event_id VARCHAR(50) NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_practice_spins_event ON practice_spins(event_id, campaign_id, created_at DESC);
+
+		CREATE TABLE IF NOT EXISTS players (
+			instagram_id VARCHAR(255) PRIMARY KEY,
+			first_seen_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			total_spins INTEGER NOT NULL DEFAULT 0,
+			wins JSONB NOT NULL DEFAULT '{}',
+			notes TEXT NOT NULL DEFAULT ''
+		);
 	`
 
-	_, err := r.pool.Exec(ctx, schema)
-	return err
+	if _, err := r.pool.Exec(ctx, schema); err != nil {
+		return err
+	}
+
+	// Build profiles from spin logs written before the players table existed
+	var hasPlayers bool
+	if err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM players)`).Scan(&hasPlayers); err != nil {
+		return err
+	}
+	if !hasPlayers {
+		return r.RebuildPlayers(ctx)
+	}
+	return nil
 }
 
 // LogSpinAsync logs a spin transaction asynchronously
@@ -68,32 +281,185 @@ func (r *PostgresRepository) LogSpinAsync(log models.SpinLog) {
 	}()
 }
 
-// LogSpin logs a spin transaction synchronously
+// LogSpin logs a spin transaction synchronously, updating the player's profile
+// in the same transaction
 func (r *PostgresRepository) LogSpin(ctx context.Context, log models.SpinLog) error {
+	err := r.logSpin(ctx, log)
+	if err != nil {
+		fmt.Printf("Failed to log spin: %v\n", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) logSpin(ctx context.Context, log models.SpinLog) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO spin_logs (instagram_id, prize_won, prize_name, was_locked, station_id, created_at, campaign_id, event_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8)
+	`, log.InstagramID, log.PrizeWon, log.PrizeName, log.WasLocked, log.StationID, log.Timestamp, r.campaign, r.events.ID())
+	if err != nil {
+		return err
+	}
+
+	if err := recordPlayerSpin(ctx, tx, log); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// recordPlayerSpin adds a spin to the player's profile, creating it on their
+// first spin. Anonymous spins have no profile.
+func recordPlayerSpin(ctx context.Context, tx pgx.Tx, log models.SpinLog) error {
+	if log.InstagramID == "" {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO players (instagram_id, first_seen_at, last_seen_at, total_spins, wins)
+		VALUES ($1, $2, $2, 1, CASE WHEN $3::text = $4::text THEN '{}'::jsonb ELSE jsonb_build_object($3::text, 1) END)
+		ON CONFLICT (instagram_id) DO UPDATE SET
+			first_seen_at = LEAST(players.first_seen_at, EXCLUDED.first_seen_at),
+			last_seen_at = GREATEST(players.last_seen_at, EXCLUDED.last_seen_at),
+			total_spins = players.total_spins + 1,
+			wins = CASE WHEN $3::text = $4::text THEN players.wins
+				ELSE players.wins || jsonb_build_object($3::text, COALESCE((players.wins->>$3::text)::int, 0) + 1) END
+	`, log.InstagramID, log.Timestamp, log.PrizeWon, noPrizeID)
+	if err != nil {
+		return fmt.Errorf("failed to update player: %w", err)
+	}
+	return nil
+}
+
+// LogWinningSpin logs a winning spin together with its voucher and claim in
+// one transaction; either may be omitted. With popSerial it also assigns the
+// prize's oldest unassigned gift card serial to the spin. Returns the spin log
+// ID and serial, ErrSerialPoolEmpty if no serial is left, or ErrDuplicate if
+// the voucher code or claim ID is already taken.
+func (r *PostgresRepository) LogWinningSpin(ctx context.Context, log models.SpinLog, voucherCode string, claim *models.Claim, popSerial bool) (int64, string, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var spinLogID int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO spin_logs (instagram_id, prize_won, prize_name, was_locked, station_id, created_at, campaign_id, event_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8)
+		RETURNING id
+	`, log.InstagramID, log.PrizeWon, log.PrizeName, log.WasLocked, log.StationID, log.Timestamp, r.campaign, r.events.ID()).Scan(&spinLogID)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to log spin: %w", err)
+	}
+
+	if err := recordPlayerSpin(ctx, tx, log); err != nil {
+		return 0, "", err
+	}
+
+	var serial string
+	if popSerial {
+		// SKIP LOCKED lets concurrent spins take different serials instead of waiting
+		err = tx.QueryRow(ctx, `
+			UPDATE gift_card_serials
+			SET spin_log_id = $1, assigned_at = NOW()
+			WHERE id = (
+				SELECT id FROM gift_card_serials
+				WHERE prize_id = $2 AND spin_log_id IS NULL
+				ORDER BY id
+				LIMIT 1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING serial
+		`, spinLogID, log.PrizeWon).Scan(&serial)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, "", ErrSerialPoolEmpty
+		}
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to assign serial: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE spin_logs SET gift_card_serial = $2 WHERE id = $1`, spinLogID, serial); err != nil {
+			return 0, "", fmt.Errorf("failed to record serial: %w", err)
+		}
+	}
+
+	if voucherCode != "" {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO vouchers (code, spin_log_id, prize_id, prize_name, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, voucherCode, spinLogID, log.PrizeWon, log.PrizeName, log.Timestamp)
+		if isUniqueViolation(err) {
+			return 0, "", ErrDuplicate
+		}
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to create voucher: %w", err)
+		}
+	}
+
+	if claim != nil {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO claims (id, spin_log_id, prize_id, issued_at, expires_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, claim.ID, spinLogID, claim.PrizeID, claim.IssuedAt, claim.ExpiresAt)
+		if isUniqueViolation(err) {
+			return 0, "", ErrDuplicate
+		}
+		if err != nil {
+			return 0, "", fmt.Errorf("failed to create claim: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, "", fmt.Errorf("failed to commit spin: %w", err)
+	}
+	return spinLogID, serial, nil
+}
+
+// LogAuditAsync records an admin audit entry asynchronously
+func (r *PostgresRepository) LogAuditAsync(entry models.AuditEntry) {
+	go func() {
+		ctx := context.Background()
+		r.LogAudit(ctx, entry)
+	}()
+}
+
+// LogAudit records an admin audit entry synchronously
+func (r *PostgresRepository) LogAudit(ctx context.Context, entry models.AuditEntry) error {
 	query := `
-		INSERT INTO spin_logs (instagram_id, prize_won, prize_name, was_locked, created_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO admin_audit_logs (action, ip_address, detail, created_at)
+		VALUES ($1, $2, $3, $4)
 	`
 
-	_, err := r.pool.Exec(ctx, query, log.InstagramID, log.PrizeWon, log.PrizeName, log.WasLocked, log.Timestamp)
+	_, err := r.pool.Exec(ctx, query, entry.Action, entry.IPAddress, entry.Detail, entry.Timestamp)
 	if err != nil {
-		fmt.Printf("Failed to log spin: %v\n", err)
+		fmt.Printf("Failed to log audit entry: %v\n", err)
 		return err
 	}
 
 	return nil
 }
 
-// GetRecentLogs fetches the most recent spin logs
+// GetRecentLogs fetches the campaign's most recent spin logs in the current event
 func (r *PostgresRepository) GetRecentLogs(ctx context.Context, limit int) ([]models.SpinLog, error) {
 	query := `
-		SELECT id, instagram_id, prize_won, prize_name, was_locked, created_at
-		FROM spin_logs
-		ORDER BY created_at DESC
+		SELECT l.id, l.instagram_id, l.prize_won, l.prize_name, l.was_locked, COALESCE(l.station_id, ''), l.created_at,
+			COALESCE(v.code, ''), COALESCE(v.status, ''), COALESCE(l.gift_card_serial, ''), l.campaign_id, l.event_id
+		FROM spin_logs l
+		LEFT JOIN vouchers v ON v.spin_log_id = l.id
+		WHERE l.campaign_id = $2 AND l.event_id = $3
+		ORDER BY l.created_at DESC
 		LIMIT $1
 	`
 
-	rows, err := r.pool.Query(ctx, query, limit)
+	rows, err := r.pool.Query(ctx, query, limit, r.campaign, r.events.ID())
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch logs: %w", err)
 	}
@@ -102,7 +468,10 @@ func (r *PostgresRepository) GetRecentLogs(ctx context.Context, limit int) ([]mo
 	var logs []models.SpinLog
 	for rows.Next() {
 		var log models.SpinLog
-		if err := rows.Scan(&log.ID, &log.InstagramID, &log.PrizeWon, &log.PrizeName, &log.WasLocked, &log.Timestamp); err != nil {
+		if err := rows.Scan(
+			&log.ID, &log.InstagramID, &log.PrizeWon, &log.PrizeName, &log.WasLocked, &log.StationID, &log.Timestamp,
+			&log.VoucherCode, &log.VoucherStatus, &log.GiftCardSerial, &log.CampaignID, &log.EventID,
+		); err != nil {
 			return nil, fmt.Errorf("failed to scan log: %w", err)
 		}
 		logs = append(logs, log)
@@ -111,12 +480,12 @@ func (r *PostgresRepository) GetRecentLogs(ctx context.Context, limit int) ([]mo
 	return logs, nil
 }
 
-// GetSpinCountByUser gets the number of spins for a specific user
+// GetSpinCountByUser gets the number of spins for a specific user in the current event
 func (r *PostgresRepository) GetSpinCountByUser(ctx context.Context, instagramID string) (int, error) {
-	query := `SELECT COUNT(*) FROM spin_logs WHERE instagram_id = $1`
+	query := `SELECT COUNT(*) FROM spin_logs WHERE instagram_id = $1 AND event_id = $2`
 
 	var count int
-	err := r.pool.QueryRow(ctx, query, instagramID).Scan(&count)
+	err := r.pool.QueryRow(ctx, query, instagramID, r.events.ID()).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get spin count: %w", err)
 	}
@@ -124,13 +493,79 @@ func (r *PostgresRepository) GetSpinCountByUser(ctx context.Context, instagramID
 	return count, nil
 }
 
-// GetStats returns overall statistics
+// GetDistinctInstagramIDs fetches every Instagram ID stored in the spin logs,
+// practice spins, player profiles and player lists, across all events
+func (r *PostgresRepository) GetDistinctInstagramIDs(ctx context.Context) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT instagram_id FROM spin_logs
+		UNION SELECT instagram_id FROM practice_spins
+		UNION SELECT instagram_id FROM players
+		UNION SELECT instagram_id FROM player_lists
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instagram ids: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan instagram id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// RenameInstagramID rewrites an Instagram ID on every spin log, practice spin,
+// player profile and list entry in one transaction, returning the number of
+// spin logs changed. If both spellings have a profile their notes are merged;
+// if both are on a list the entry under the new spelling is kept. Vouchers,
+// claims and gift card serials reference spin logs by ID and need no change.
+func (r *PostgresRepository) RenameInstagramID(ctx context.Context, from, to string) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `UPDATE spin_logs SET instagram_id = $2 WHERE instagram_id = $1`, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to rename instagram id: %w", err)
+	}
+
+	statements := []string{
+		`UPDATE practice_spins SET instagram_id = $2 WHERE instagram_id = $1`,
+		`UPDATE players p SET notes = CONCAT_WS(E'\n', NULLIF(p.notes, ''), NULLIF(o.notes, ''))
+			FROM players o WHERE p.instagram_id = $2 AND o.instagram_id = $1`,
+		`UPDATE players SET instagram_id = $2
+			WHERE instagram_id = $1 AND NOT EXISTS (SELECT 1 FROM players WHERE instagram_id = $2)`,
+		`DELETE FROM players WHERE instagram_id = $1`,
+		`UPDATE player_lists SET instagram_id = $2
+			WHERE instagram_id = $1 AND NOT EXISTS (SELECT 1 FROM player_lists WHERE instagram_id = $2)`,
+		`DELETE FROM player_lists WHERE instagram_id = $1`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(ctx, statement, from, to); err != nil {
+			return 0, fmt.Errorf("failed to rename instagram id: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit rename: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetStats returns the campaign's statistics for the current event
 func (r *PostgresRepository) GetStats(ctx context.Context) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
 
 	// Total spins
 	var totalSpins int
-	err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM spin_logs").Scan(&totalSpins)
+	err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM spin_logs WHERE campaign_id = $1 AND event_id = $2", r.campaign, r.events.ID()).Scan(&totalSpins)
 	if err != nil {
 		return nil, err
 	}
@@ -140,9 +575,10 @@ func (r *PostgresRepository) GetStats(ctx context.Context) (map[string]interface
 	rows, err := r.pool.Query(ctx, `
 		SELECT prize_won, COUNT(*) as count 
 		FROM spin_logs 
+		WHERE campaign_id = $1 AND event_id = $2
 		GROUP BY prize_won 
 		ORDER BY count DESC
-	`)
+	`, r.campaign, r.events.ID())
 	if err != nil {
 		return nil, err
 	}
@@ -161,3 +597,980 @@ func (r *PostgresRepository) GetStats(ctx context.Context) (map[string]interface
 
 	return stats, nil
 }
+
+// GetAdminUser fetches an admin user by username.
+// Returns nil if the user does not exist.
+func (r *PostgresRepository) GetAdminUser(ctx context.Context, username string) (*models.AdminUser, error) {
+	query := `
+		SELECT id, username, password_hash, role, totp_secret, totp_enabled, created_at
+		FROM admin_users
+		WHERE username = $1
+	`
+
+	var user models.AdminUser
+	err := r.pool.QueryRow(ctx, query, username).Scan(
+		&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.TOTPSecret, &user.TOTPEnabled, &user.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin user: %w", err)
+	}
+
+	return &user, nil
+}
+
+// CreateAdminUser inserts a new admin user
+func (r *PostgresRepository) CreateAdminUser(ctx context.Context, user models.AdminUser) error {
+	query := `
+		INSERT INTO admin_users (username, password_hash, role)
+		VALUES ($1, $2, $3)
+	`
+
+	if _, err := r.pool.Exec(ctx, query, user.Username, user.PasswordHash, user.Role); err != nil {
+		return fmt.Errorf("failed to create admin user: %w", err)
+	}
+	return nil
+}
+
+// SetTOTPSecret stores a pending TOTP secret and replaces the user's recovery codes.
+// TOTP stays disabled until EnableTOTP is called.
+func (r *PostgresRepository) SetTOTPSecret(ctx context.Context, userID int64, secret string, recoveryCodeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE admin_users SET totp_secret = $2, totp_enabled = FALSE WHERE id = $1`, userID, secret); err != nil {
+		return fmt.Errorf("failed to set TOTP secret: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM admin_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to clear recovery codes: %w", err)
+	}
+	for _, hash := range recoveryCodeHashes {
+		if _, err := tx.Exec(ctx, `INSERT INTO admin_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash); err != nil {
+			return fmt.Errorf("failed to store recovery code: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// EnableTOTP turns on TOTP for a user after enrollment has been confirmed
+func (r *PostgresRepository) EnableTOTP(ctx context.Context, userID int64) error {
+	if _, err := r.pool.Exec(ctx, `UPDATE admin_users SET totp_enabled = TRUE WHERE id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to enable TOTP: %w", err)
+	}
+	return nil
+}
+
+// UseRecoveryCode consumes an unused recovery code.
+// Returns false if the code does not exist or was already used.
+func (r *PostgresRepository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	query := `
+		UPDATE admin_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	tag, err := r.pool.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// CreateStation registers a kiosk with the hash of its API key
+func (r *PostgresRepository) CreateStation(ctx context.Context, station models.Station, keyHash string) error {
+	query := `
+		INSERT INTO stations (id, name, key_hash, key_prefix, signing_secret)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	if _, err := r.pool.Exec(ctx, query, station.ID, station.Name, keyHash, station.KeyPrefix, station.SigningSecret); err != nil {
+		return fmt.Errorf("failed to create station: %w", err)
+	}
+	return nil
+}
+
+// GetStation fetches a station by ID, including revoked ones.
+// Returns nil if the station does not exist.
+func (r *PostgresRepository) GetStation(ctx context.Context, id string) (*models.Station, error) {
+	query := `
+		SELECT id, name, key_prefix, created_at, last_seen_at, revoked_at
+		FROM stations
+		WHERE id = $1
+	`
+
+	var station models.Station
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&station.ID, &station.Name, &station.KeyPrefix, &station.CreatedAt, &station.LastSeenAt, &station.RevokedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get station: %w", err)
+	}
+
+	return &station, nil
+}
+
+// GetStationByKeyHash fetches an active (not revoked) station by API key hash.
+// Returns nil if no active station matches.
+func (r *PostgresRepository) GetStationByKeyHash(ctx context.Context, keyHash string) (*models.Station, error) {
+	query := `
+		SELECT id, name, key_prefix, signing_secret, created_at, last_seen_at, revoked_at
+		FROM stations
+		WHERE key_hash = $1 AND revoked_at IS NULL
+	`
+
+	var station models.Station
+	err := r.pool.QueryRow(ctx, query, keyHash).Scan(
+		&station.ID, &station.Name, &station.KeyPrefix, &station.SigningSecret, &station.CreatedAt, &station.LastSeenAt, &station.RevokedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get station: %w", err)
+	}
+
+	return &station, nil
+}
+
+// ListStations returns all registered stations, including revoked ones
+func (r *PostgresRepository) ListStations(ctx context.Context) ([]models.Station, error) {
+	query := `
+		SELECT id, name, key_prefix, created_at, last_seen_at, revoked_at
+		FROM stations
+		ORDER BY created_at
+	`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch stations: %w", err)
+	}
+	defer rows.Close()
+
+	var stations []models.Station
+	for rows.Next() {
+		var station models.Station
+		if err := rows.Scan(&station.ID, &station.Name, &station.KeyPrefix, &station.CreatedAt, &station.LastSeenAt, &station.RevokedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan station: %w", err)
+		}
+		stations = append(stations, station)
+	}
+
+	return stations, nil
+}
+
+// RevokeStation disables a station's API key.
+// Returns false if the station does not exist or is already revoked.
+func (r *PostgresRepository) RevokeStation(ctx context.Context, id string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `UPDATE stations SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke station: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// SetStationSigningSecret replaces the request signing secret of an active station.
+// Returns false if the station does not exist or is revoked.
+func (r *PostgresRepository) SetStationSigningSecret(ctx context.Context, id, secret string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `UPDATE stations SET signing_secret = $2 WHERE id = $1 AND revoked_at IS NULL`, id, secret)
+	if err != nil {
+		return false, fmt.Errorf("failed to set signing secret: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// TouchStationAsync records that a station was seen, without blocking the request
+func (r *PostgresRepository) TouchStationAsync(id string) {
+	go func() {
+		ctx := context.Background()
+		if _, err := r.pool.Exec(ctx, `UPDATE stations SET last_seen_at = NOW() WHERE id = $1`, id); err != nil {
+			fmt.Printf("Failed to update station last seen: %v\n", err)
+		}
+	}()
+}
+
+// GetVoucher fetches a voucher by code.
+// Returns nil if the voucher does not exist.
+func (r *PostgresRepository) GetVoucher(ctx context.Context, code string) (*models.Voucher, error) {
+	query := `
+		SELECT id, code, spin_log_id, prize_id, prize_name, status, created_at, redeemed_at, redeemed_by, voided_at, void_reason
+		FROM vouchers
+		WHERE code = $1
+	`
+
+	var v models.Voucher
+	err := r.pool.QueryRow(ctx, query, code).Scan(
+		&v.ID, &v.Code, &v.SpinLogID, &v.PrizeID, &v.PrizeName, &v.Status, &v.CreatedAt,
+		&v.RedeemedAt, &v.RedeemedBy, &v.VoidedAt, &v.VoidReason,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voucher: %w", err)
+	}
+
+	return &v, nil
+}
+
+// RedeemVoucher marks an issued voucher as redeemed.
+// Returns false if the voucher does not exist or is not in the issued state.
+func (r *PostgresRepository) RedeemVoucher(ctx context.Context, code, redeemedBy string) (bool, error) {
+	query := `
+		UPDATE vouchers
+		SET status = 'redeemed', redeemed_at = NOW(), redeemed_by = $2
+		WHERE code = $1 AND status = 'issued'
+	`
+
+	tag, err := r.pool.Exec(ctx, query, code, redeemedBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to redeem voucher: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// VoidVoucher cancels an issued voucher.
+// Returns false if the voucher does not exist or is not in the issued state.
+func (r *PostgresRepository) VoidVoucher(ctx context.Context, code, reason string) (bool, error) {
+	query := `
+		UPDATE vouchers
+		SET status = 'void', voided_at = NOW(), void_reason = $2
+		WHERE code = $1 AND status = 'issued'
+	`
+
+	tag, err := r.pool.Exec(ctx, query, code, reason)
+	if err != nil {
+		return false, fmt.Errorf("failed to void voucher: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// GetClaim fetches a claim by its public ID.
+// Returns nil if the claim does not exist.
+func (r *PostgresRepository) GetClaim(ctx context.Context, id string) (*models.Claim, error) {
+	return r.getClaim(ctx, `WHERE c.id = $1`, id)
+}
+
+// GetClaimBySpinLog fetches the claim for a spin.
+// Returns nil if the spin has no claim.
+func (r *PostgresRepository) GetClaimBySpinLog(ctx context.Context, spinLogID int64) (*models.Claim, error) {
+	return r.getClaim(ctx, `WHERE c.spin_log_id = $1`, spinLogID)
+}
+
+func (r *PostgresRepository) getClaim(ctx context.Context, where string, arg interface{}) (*models.Claim, error) {
+	query := `
+		SELECT c.id, c.spin_log_id, c.prize_id, c.issued_at, c.expires_at, c.claimed_at, c.claimed_by,
+			COALESCE(l.gift_card_serial, '')
+		FROM claims c
+		JOIN spin_logs l ON l.id = c.spin_log_id
+	` + where
+
+	var claim models.Claim
+	err := r.pool.QueryRow(ctx, query, arg).Scan(
+		&claim.ID, &claim.SpinLogID, &claim.PrizeID, &claim.IssuedAt, &claim.ExpiresAt, &claim.ClaimedAt, &claim.ClaimedBy,
+		&claim.GiftCardSerial,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get claim: %w", err)
+	}
+
+	return &claim, nil
+}
+
+// MarkClaimed records that a spin's prize was handed over.
+// Returns false if the spin has no claim or it was already claimed.
+func (r *PostgresRepository) MarkClaimed(ctx context.Context, spinLogID int64, claimedBy string) (bool, error) {
+	query := `
+		UPDATE claims
+		SET claimed_at = NOW(), claimed_by = $2
+		WHERE spin_log_id = $1 AND claimed_at IS NULL
+	`
+
+	tag, err := r.pool.Exec(ctx, query, spinLogID, claimedBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark claim: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ImportSerials adds gift card serials to a prize's pool, skipping serials
+// already imported. Returns how many were added.
+func (r *PostgresRepository) ImportSerials(ctx context.Context, prizeID string, serials []string) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	imported := 0
+	for _, serial := range serials {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO gift_card_serials (prize_id, serial)
+			VALUES ($1, $2)
+			ON CONFLICT (serial) DO NOTHING
+		`, prizeID, serial)
+		if err != nil {
+			return 0, fmt.Errorf("failed to import serial: %w", err)
+		}
+		imported += int(tag.RowsAffected())
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit serials: %w", err)
+	}
+	return imported, nil
+}
+
+// GetSerialPoolCounts returns how many serials a prize has left and in total
+func (r *PostgresRepository) GetSerialPoolCounts(ctx context.Context, prizeID string) (available, total int, err error) {
+	query := `
+		SELECT COUNT(*) FILTER (WHERE spin_log_id IS NULL), COUNT(*)
+		FROM gift_card_serials
+		WHERE prize_id = $1
+	`
+
+	err = r.pool.QueryRow(ctx, query, prizeID).Scan(&available, &total)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count serials: %w", err)
+	}
+	return available, total, nil
+}
+
+// CountSerialSpins counts the campaign's spins in the current event that were
+// assigned a gift card serial for a prize. Wins logged before the prize became
+// a serial pool have no serial and are not counted.
+func (r *PostgresRepository) CountSerialSpins(ctx context.Context, prizeID string) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM spin_logs
+		WHERE prize_won = $1 AND gift_card_serial IS NOT NULL AND campaign_id = $2 AND event_id = $3
+	`
+
+	var count int
+	err := r.pool.QueryRow(ctx, query, prizeID, r.campaign, r.events.ID()).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count serial spins: %w", err)
+	}
+	return count, nil
+}
+
+// AppendStockLedger records a stock change
+func (r *PostgresRepository) AppendStockLedger(ctx context.Context, entry models.StockLedgerEntry) error {
+	query := `
+		INSERT INTO stock_ledger (prize_id, kind, change, stock_after, reason, actor, created_at, campaign_id, event_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.pool.Exec(ctx, query, entry.PrizeID, entry.Kind, entry.Change, entry.StockAfter, entry.Reason, entry.Actor, entry.Timestamp, r.campaign, r.events.ID())
+	if err != nil {
+		fmt.Printf("Failed to append stock ledger: %v\n", err)
+		return err
+	}
+
+	return nil
+}
+
+// AppendStockLedgerAsync records a stock change asynchronously
+func (r *PostgresRepository) AppendStockLedgerAsync(entry models.StockLedgerEntry) {
+	go func() {
+		ctx := context.Background()
+		r.AppendStockLedger(ctx, entry)
+	}()
+}
+
+// GetStockLedgerAnchor fetches the latest entry that set a prize's stock
+// outright (seed, reset or adjust). Returns nil if the prize has none.
+func (r *PostgresRepository) GetStockLedgerAnchor(ctx context.Context, prizeID string) (*models.StockLedgerEntry, error) {
+	query := `
+		SELECT id, prize_id, kind, change, stock_after, reason, actor, created_at
+		FROM stock_ledger
+		WHERE prize_id = $1 AND kind IN ($2, $3, $4) AND campaign_id = $5 AND event_id = $6
+		ORDER BY id DESC
+		LIMIT 1
+	`
+
+	var entry models.StockLedgerEntry
+	err := r.pool.QueryRow(ctx, query, prizeID, models.StockLedgerSeed, models.StockLedgerReset, models.StockLedgerAdjust, r.campaign, r.events.ID()).Scan(
+		&entry.ID, &entry.PrizeID, &entry.Kind, &entry.Change, &entry.StockAfter, &entry.Reason, &entry.Actor, &entry.Timestamp,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock ledger anchor: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// GetStockLedgerSince sums a prize's ledger changes after an entry ID, and
+// how many units were awarded net of restores
+func (r *PostgresRepository) GetStockLedgerSince(ctx context.Context, prizeID string, afterID int64) (change, awards int, err error) {
+	query := `
+		SELECT COALESCE(SUM(change), 0), COALESCE(-SUM(change) FILTER (WHERE kind IN ($3, $4)), 0)
+		FROM stock_ledger
+		WHERE prize_id = $1 AND id > $2 AND campaign_id = $5 AND event_id = $6
+	`
+
+	err = r.pool.QueryRow(ctx, query, prizeID, afterID, models.StockLedgerAward, models.StockLedgerRestore, r.campaign, r.events.ID()).Scan(&change, &awards)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to sum stock ledger: %w", err)
+	}
+	return change, awards, nil
+}
+
+// CountPrizeSpinsSince counts spins that won a prize after a point in time
+func (r *PostgresRepository) CountPrizeSpinsSince(ctx context.Context, prizeID string, since time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM spin_logs WHERE prize_won = $1 AND created_at > $2 AND campaign_id = $3 AND event_id = $4`
+
+	var count int
+	err := r.pool.QueryRow(ctx, query, prizeID, since, r.campaign, r.events.ID()).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count prize spins: %w", err)
+	}
+
+	return count, nil
+}
+
+// CreateWebhookDelivery queues an event for delivery
+func (r *PostgresRepository) CreateWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (event_id, event_type, url, payload, subscription_id, created_at, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+	`
+
+	_, err := r.pool.Exec(ctx, query, delivery.EventID, delivery.EventType, delivery.URL, string(delivery.Payload), delivery.SubscriptionID, delivery.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to queue webhook: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueWebhookDeliveries leases up to limit pending deliveries that are due
+// by pushing their next attempt to leaseUntil, so a crashed sender's
+// deliveries are picked up again once the lease expires. Subscription
+// deliveries come with the subscription's signing secret.
+func (r *PostgresRepository) ClaimDueWebhookDeliveries(ctx context.Context, limit int, leaseUntil time.Time) ([]models.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = $2
+		FROM (
+			SELECT pending.id, COALESCE(s.secret, '') AS secret
+			FROM webhook_deliveries pending
+			LEFT JOIN webhook_subscriptions s ON s.id = pending.subscription_id
+			WHERE pending.status = 'pending' AND pending.next_attempt_at <= NOW()
+			ORDER BY pending.id
+			LIMIT $1
+			FOR UPDATE OF pending SKIP LOCKED
+		) due
+		WHERE d.id = due.id
+		RETURNING d.id, d.subscription_id, due.secret, d.event_id, d.event_type, d.url, d.payload, d.attempts
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit, leaseUntil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		var payload string
+		if err := rows.Scan(
+			&delivery.ID, &delivery.SubscriptionID, &delivery.Secret, &delivery.EventID, &delivery.EventType, &delivery.URL, &payload, &delivery.Attempts,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		delivery.Payload = json.RawMessage(payload)
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// RecordWebhookAttempt stores the outcome of a delivery attempt. The delivery
+// moves to status, and if still pending is retried at nextAttemptAt.
+func (r *PostgresRepository) RecordWebhookAttempt(ctx context.Context, id int64, status string, statusCode int, lastError string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = attempts + 1, last_status_code = $3, last_error = $4, next_attempt_at = $5,
+			delivered_at = CASE WHEN $2 = 'delivered' THEN NOW() ELSE delivered_at END
+		WHERE id = $1
+	`
+
+	_, err := r.pool.Exec(ctx, query, id, status, statusCode, lastError, nextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+// ListWebhookDeliveries fetches the most recent deliveries, optionally only those in a status
+func (r *PostgresRepository) ListWebhookDeliveries(ctx context.Context, status string, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		SELECT id, subscription_id, event_id, event_type, url, payload, status, attempts, next_attempt_at,
+			last_status_code, last_error, created_at, delivered_at
+		FROM webhook_deliveries
+		WHERE $1::text = '' OR status = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		var payload string
+		if err := rows.Scan(
+			&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &delivery.URL, &payload, &delivery.Status, &delivery.Attempts,
+			&delivery.NextAttemptAt, &delivery.LastStatusCode, &delivery.LastError, &delivery.CreatedAt, &delivery.DeliveredAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		delivery.Payload = json.RawMessage(payload)
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+// RetryWebhookDelivery puts a failed delivery back in the queue with fresh attempts.
+// Returns false if the delivery does not exist or has not failed.
+func (r *PostgresRepository) RetryWebhookDelivery(ctx context.Context, id int64) (bool, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), last_error = ''
+		WHERE d.id = $1 AND d.status = 'failed' AND NOT EXISTS (
+			SELECT 1 FROM webhook_subscriptions s WHERE s.id = d.subscription_id AND s.deleted_at IS NOT NULL
+		)
+	`
+
+	tag, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to retry webhook delivery: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// CreateWebhookSubscription stores a subscription and returns its ID
+func (r *PostgresRepository) CreateWebhookSubscription(ctx context.Context, sub models.WebhookSubscription) (int64, error) {
+	query := `
+		INSERT INTO webhook_subscriptions (url, event_types, secret, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	var id int64
+	err := r.pool.QueryRow(ctx, query, sub.URL, sub.EventTypes, sub.Secret, sub.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return id, nil
+}
+
+// ListWebhookSubscriptions fetches active subscriptions, or only those
+// receiving eventType if it is not empty
+func (r *PostgresRepository) ListWebhookSubscriptions(ctx context.Context, eventType string) ([]models.WebhookSubscription, error) {
+	query := `
+		SELECT id, url, event_types, secret, created_at
+		FROM webhook_subscriptions
+		WHERE deleted_at IS NULL AND ($1::text = '' OR $1 = ANY(event_types))
+		ORDER BY id
+	`
+
+	rows, err := r.pool.Query(ctx, query, eventType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []models.WebhookSubscription{}
+	for rows.Next() {
+		var sub models.WebhookSubscription
+		if err := rows.Scan(&sub.ID, &sub.URL, &sub.EventTypes, &sub.Secret, &sub.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, nil
+}
+
+// DeleteWebhookSubscription stops a subscription and fails its pending deliveries.
+// Returns false if the subscription does not exist or was already deleted.
+func (r *PostgresRepository) DeleteWebhookSubscription(ctx context.Context, id int64) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `UPDATE webhook_subscriptions SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'failed', last_error = 'subscription deleted'
+		WHERE subscription_id = $1 AND status = 'pending'
+	`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel webhook deliveries: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit subscription delete: %w", err)
+	}
+	return true, nil
+}
+
+// GetCurrentEvent fetches the event that is not archived. Returns nil if there is none.
+func (r *PostgresRepository) GetCurrentEvent(ctx context.Context) (*models.Event, error) {
+	query := `
+		SELECT id, name, started_at, started_by, archived_at
+		FROM events
+		WHERE archived_at IS NULL
+	`
+
+	var event models.Event
+	err := r.pool.QueryRow(ctx, query).Scan(&event.ID, &event.Name, &event.StartedAt, &event.StartedBy, &event.ArchivedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current event: %w", err)
+	}
+
+	return &event, nil
+}
+
+// StartEvent archives the current event and makes a new one current. Spin
+// logs and ledger entries of the archived event are kept. Returns ErrDuplicate
+// if an event with the ID already exists.
+func (r *PostgresRepository) StartEvent(ctx context.Context, event models.Event) (archived *models.Event, err error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var current models.Event
+	err = tx.QueryRow(ctx, `
+		UPDATE events SET archived_at = $1
+		WHERE archived_at IS NULL
+		RETURNING id, name, started_at, started_by, archived_at
+	`, event.StartedAt).Scan(&current.ID, &current.Name, &current.StartedAt, &current.StartedBy, &current.ArchivedAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		return nil, fmt.Errorf("failed to archive event: %w", err)
+	default:
+		archived = &current
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO events (id, name, started_at, started_by)
+		VALUES ($1, $2, $3, $4)
+	`, event.ID, event.Name, event.StartedAt, event.StartedBy)
+	if isUniqueViolation(err) {
+		return nil, ErrDuplicate
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to start event: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return archived, nil
+}
+
+// ListEvents fetches every event, newest first, with its number of spins
+func (r *PostgresRepository) ListEvents(ctx context.Context) ([]models.Event, error) {
+	query := `
+		SELECT e.id, e.name, e.started_at, e.started_by, e.archived_at,
+			(SELECT COUNT(*) FROM spin_logs l WHERE l.event_id = e.id)
+		FROM events e
+		ORDER BY e.started_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.Event{}
+	for rows.Next() {
+		var event models.Event
+		if err := rows.Scan(&event.ID, &event.Name, &event.StartedAt, &event.StartedBy, &event.ArchivedAt, &event.TotalSpins); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetPlayer fetches a player's profile by normalized Instagram ID. Returns nil if they never spun.
+func (r *PostgresRepository) GetPlayer(ctx context.Context, instagramID string) (*models.Player, error) {
+	query := `
+		SELECT instagram_id, first_seen_at, last_seen_at, total_spins, wins, notes
+		FROM players
+		WHERE instagram_id = $1
+	`
+
+	var player models.Player
+	err := r.pool.QueryRow(ctx, query, instagramID).Scan(
+		&player.InstagramID, &player.FirstSeenAt, &player.LastSeenAt, &player.TotalSpins, &player.Wins, &player.Notes,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get player: %w", err)
+	}
+
+	return &player, nil
+}
+
+// GetPlayerSpins fetches a player's most recent spins across all events and campaigns
+func (r *PostgresRepository) GetPlayerSpins(ctx context.Context, instagramID string, limit int) ([]models.SpinLog, error) {
+	query := `
+		SELECT l.id, l.instagram_id, l.prize_won, l.prize_name, l.was_locked, COALESCE(l.station_id, ''), l.created_at,
+			COALESCE(v.code, ''), COALESCE(v.status, ''), COALESCE(l.gift_card_serial, ''), l.campaign_id, l.event_id
+		FROM spin_logs l
+		LEFT JOIN vouchers v ON v.spin_log_id = l.id
+		WHERE l.instagram_id = $1
+		ORDER BY l.created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, instagramID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch player spins: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []models.SpinLog{}
+	for rows.Next() {
+		var log models.SpinLog
+		if err := rows.Scan(
+			&log.ID, &log.InstagramID, &log.PrizeWon, &log.PrizeName, &log.WasLocked, &log.StationID, &log.Timestamp,
+			&log.VoucherCode, &log.VoucherStatus, &log.GiftCardSerial, &log.CampaignID, &log.EventID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan log: %w", err)
+		}
+		logs = append(logs, log)
+	}
+
+	return logs, rows.Err()
+}
+
+// SetPlayerNotes replaces the staff notes on a player's profile. Returns false if there is no such player.
+func (r *PostgresRepository) SetPlayerNotes(ctx context.Context, instagramID, notes string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `UPDATE players SET notes = $2 WHERE instagram_id = $1`, instagramID, notes)
+	if err != nil {
+		return false, fmt.Errorf("failed to update player notes: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// RebuildPlayers recomputes every player's profile from the spin logs, keeping
+// their notes. Profiles with no spins left are removed unless they have notes.
+func (r *PostgresRepository) RebuildPlayers(ctx context.Context) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO players (instagram_id, first_seen_at, last_seen_at, total_spins, wins)
+		SELECT s.instagram_id, MIN(s.created_at), MAX(s.created_at), COUNT(*),
+			COALESCE((
+				SELECT jsonb_object_agg(w.prize_won, w.wins)
+				FROM (
+					SELECT prize_won, COUNT(*) AS wins
+					FROM spin_logs
+					WHERE instagram_id = s.instagram_id AND prize_won <> $1
+					GROUP BY prize_won
+				) w
+			), '{}'::jsonb)
+		FROM spin_logs s
+		WHERE s.instagram_id <> ''
+		GROUP BY s.instagram_id
+		ON CONFLICT (instagram_id) DO UPDATE SET
+			first_seen_at = EXCLUDED.first_seen_at,
+			last_seen_at = EXCLUDED.last_seen_at,
+			total_spins = EXCLUDED.total_spins,
+			wins = EXCLUDED.wins
+	`, noPrizeID)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild players: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM players p
+		WHERE p.notes = '' AND NOT EXISTS (SELECT 1 FROM spin_logs l WHERE l.instagram_id = p.instagram_id)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to remove stale players: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetPlayerList returns the list an Instagram ID is on, or "" if it is on none
+func (r *PostgresRepository) GetPlayerList(ctx context.Context, instagramID string) (string, error) {
+	var list string
+	err := r.pool.QueryRow(ctx, `SELECT list FROM player_lists WHERE instagram_id = $1`, instagramID).Scan(&list)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get player list: %w", err)
+	}
+	return list, nil
+}
+
+// ListPlayers fetches the Instagram IDs on a list, most recently added first
+func (r *PostgresRepository) ListPlayers(ctx context.Context, list string) ([]models.ListedPlayer, error) {
+	query := `
+		SELECT instagram_id, list, reason, added_by, added_at
+		FROM player_lists
+		WHERE list = $1
+		ORDER BY added_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, list)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list players: %w", err)
+	}
+	defer rows.Close()
+
+	players := []models.ListedPlayer{}
+	for rows.Next() {
+		var player models.ListedPlayer
+		if err := rows.Scan(&player.InstagramID, &player.List, &player.Reason, &player.AddedBy, &player.AddedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan listed player: %w", err)
+		}
+		players = append(players, player)
+	}
+
+	return players, rows.Err()
+}
+
+// AddToPlayerList puts Instagram IDs on their list in one transaction, moving
+// any that are on the other list
+func (r *PostgresRepository) AddToPlayerList(ctx context.Context, players []models.ListedPlayer) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, player := range players {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO player_lists (instagram_id, list, reason, added_by, added_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (instagram_id) DO UPDATE SET
+				list = EXCLUDED.list,
+				reason = EXCLUDED.reason,
+				added_by = EXCLUDED.added_by,
+				added_at = EXCLUDED.added_at
+		`, player.InstagramID, player.List, player.Reason, player.AddedBy, player.AddedAt)
+		if err != nil {
+			return fmt.Errorf("failed to add player to list: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit list: %w", err)
+	}
+	return nil
+}
+
+// RemoveFromPlayerList takes an Instagram ID off a list. Returns false if it was not on it.
+func (r *PostgresRepository) RemoveFromPlayerList(ctx context.Context, list, instagramID string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM player_lists WHERE list = $1 AND instagram_id = $2`, list, instagramID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove player from list: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// LogPracticeSpinAsync logs a staff practice spin asynchronously
+func (r *PostgresRepository) LogPracticeSpinAsync(log models.SpinLog) {
+	go func() {
+		ctx := context.Background()
+		r.LogPracticeSpin(ctx, log)
+	}()
+}
+
+// LogPracticeSpin logs a staff practice spin, apart from the spin logs so it
+// does not count in stats, stock or player profiles
+func (r *PostgresRepository) LogPracticeSpin(ctx context.Context, log models.SpinLog) error {
+	query := `
+		INSERT INTO practice_spins (instagram_id, prize_won, prize_name, station_id, created_at, campaign_id, event_id)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7)
+	`
+
+	_, err := r.pool.Exec(ctx, query, log.InstagramID, log.PrizeWon, log.PrizeName, log.StationID, log.Timestamp, r.campaign, r.events.ID())
+	if err != nil {
+		fmt.Printf("Failed to log practice spin: %v\n", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetRecentPracticeSpins fetches the campaign's most recent practice spins in the current event
+func (r *PostgresRepository) GetRecentPracticeSpins(ctx context.Context, limit int) ([]models.SpinLog, error) {
+	query := `
+		SELECT id, instagram_id, prize_won, prize_name, COALESCE(station_id, ''), created_at, campaign_id, event_id
+		FROM practice_spins
+		WHERE campaign_id = $2 AND event_id = $3
+		ORDER BY created_at DESC
+		LIMIT $1
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit, r.campaign, r.events.ID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch practice spins: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []models.SpinLog{}
+	for rows.Next() {
+		var log models.SpinLog
+		if err := rows.Scan(
+			&log.ID, &log.InstagramID, &log.PrizeWon, &log.PrizeName, &log.StationID, &log.Timestamp, &log.CampaignID, &log.EventID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan practice spin: %w", err)
+		}
+		logs = append(logs, log)
+	}
+
+	return logs, rows.Err()
+}
@@ -2,11 +2,14 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -14,16 +17,68 @@ const (
 	// Redis key prefixes
 	stockKeyPrefix = "stock:"
 	lockKey        = "config:next_prize_lock"
+
+	// Per-station prize locks, a hash of station ID to prize ID
+	stationLockKey = "config:station_prize_locks"
+
+	// Booth pause as JSON, expiring at the auto-resume time if there is one
+	boothPauseKey = "config:booth_pause"
+
+	// Admin override of the event opening hours, "open" or "closed"
+	eventOverrideKey = "config:event_override"
+
+	// Set while winner announcements on a campaign's display feed are paused
+	displayPausedKey = "config:display_paused"
+
+	// Admin login brute-force tracking, suffixed with a scope ("ip:<addr>" or "global")
+	authFailKeyPrefix    = "auth:fail:"
+	authLockoutKeyPrefix = "auth:lockout:"
+	authStrikeKeyPrefix  = "auth:strikes:"
+
+	// Admin sessions keyed by token hash, and TOTP time steps already used per user
+	sessionKeyPrefix  = "session:"
+	totpUsedKeyPrefix = "totp:used:"
+
+	// Signed spin request nonces already seen, per station
+	nonceKeyPrefix = "nonce:"
+
+	// Spin results stored per Idempotency-Key
+	idempotencyKeyPrefix = "idempotency:"
+	idempotencyPending   = "pending"
+
+	// Event feeds are a capped stream for Last-Event-ID replay, and a pub/sub
+	// channel of the same name that fans new events out to every backend instance
+	liveStreamMaxLen = 1000
+
+	// Kiosk remote control: commands and acks are fanned out to every backend
+	// instance, since a kiosk and the admin sending it a command may be on
+	// different ones. Online kiosks are a sorted set scored by last seen, and
+	// the campaign each kiosk spins is a hash by station.
+	kioskCommandChannel = "kiosk:commands"
+	kioskAckChannel     = "kiosk:acks"
+	kioskOnlineKey      = "kiosk:online"
+	kioskCampaignsKey   = "kiosk:campaigns"
+
+	// Announces a newly started event to every backend instance
+	eventStartedChannel = "events:started"
+)
+
+// Event feeds served over SSE
+const (
+	AdminFeed   = "live:events"           // spins, locks and stock changes for admins
+	DisplayFeed = "display:announcements" // masked winners for the public display
 )
 
 // RedisRepository handles Redis operations
 type RedisRepository struct {
 	client *redis.Client
 	config *config.Config
+	events *EventScope
+	prefix string // prepended to campaign keys: stock, locks and idempotency
 }
 
 // NewRedisRepository creates a new Redis repository
-func NewRedisRepository(addr string, cfg *config.Config) (*RedisRepository, error) {
+func NewRedisRepository(addr string, cfg *config.Config, events *EventScope) (*RedisRepository, error) {
 	var opts *redis.Options
 	var err error
 
@@ -53,11 +108,39 @@ func NewRedisRepository(addr string, cfg *config.Config) (*RedisRepository, erro
 	repo := &RedisRepository{
 		client: client,
 		config: cfg,
+		events: events,
 	}
 
 	return repo, nil
 }
 
+// ForCampaign returns a repository for a campaign's stock, lock and
+// idempotency keys, sharing this connection. cfg is the campaign's config.
+// The default campaign keeps the unprefixed keys.
+func (r *RedisRepository) ForCampaign(cfg *config.Config, campaignID string) *RedisRepository {
+	scoped := &RedisRepository{client: r.client, config: cfg, events: r.events, prefix: r.prefix}
+	if campaignID != config.DefaultCampaignID {
+		scoped.prefix += "campaign:" + campaignID + ":"
+	}
+	return scoped
+}
+
+// Feed returns the name of a campaign's own copy of an event feed. Feeds
+// outlive events, so only the campaign prefix is applied.
+func (r *RedisRepository) Feed(feed string) string {
+	return r.prefix + feed
+}
+
+// key prefixes a campaign key with the current event and the campaign
+func (r *RedisRepository) key(k string) string {
+	return r.events.keyPrefix() + r.prefix + k
+}
+
+// eventKey prefixes a key shared by all campaigns with the current event
+func (r *RedisRepository) eventKey(k string) string {
+	return r.events.keyPrefix() + k
+}
+
 // Close closes the Redis connection
 func (r *RedisRepository) Close() error {
 	return r.client.Close()
@@ -67,7 +150,7 @@ func (r *RedisRepository) Close() error {
 func (r *RedisRepository) InitializeStocks(ctx context.Context) error {
 	for _, prize := range r.config.Prizes {
 		if prize.Stock > 0 {
-			key := stockKeyPrefix + prize.ID
+			key := r.key(stockKeyPrefix + prize.ID)
 			if err := r.client.Set(ctx, key, prize.Stock, 0).Err(); err != nil {
 				return fmt.Errorf("failed to set stock for %s: %w", prize.ID, err)
 			}
@@ -79,15 +162,60 @@ func (r *RedisRepository) InitializeStocks(ctx context.Context) error {
 // ResetStocks resets all stocks to default values
 func (r *RedisRepository) ResetStocks(ctx context.Context) error {
 	// Clear any existing lock
-	r.client.Del(ctx, lockKey)
+	r.client.Del(ctx, r.key(lockKey), r.key(stationLockKey))
 
 	// Reset stocks
 	return r.InitializeStocks(ctx)
 }
 
-// GetNextPrizeLock checks if there's a locked prize for the next spin
+// takeLockScript removes and returns the station's lock, or else the global lock
+var takeLockScript = redis.NewScript(`
+local prize = redis.call('HGET', KEYS[1], ARGV[1])
+if prize then
+	redis.call('HDEL', KEYS[1], ARGV[1])
+	return prize
+end
+prize = redis.call('GET', KEYS[2])
+if prize then
+	redis.call('DEL', KEYS[2])
+	return prize
+end
+return ''
+`)
+
+// TakeNextPrizeLock removes and returns the lock for a station's next spin:
+// the station's own lock if set, otherwise the global lock. Only one spin can
+// take a lock.
+func (r *RedisRepository) TakeNextPrizeLock(ctx context.Context, stationID string) (string, error) {
+	prizeID, err := takeLockScript.Run(ctx, r.client, []string{r.key(stationLockKey), r.key(lockKey)}, stationID).Text()
+	if err != nil {
+		return "", fmt.Errorf("failed to take lock: %w", err)
+	}
+	return prizeID, nil
+}
+
+// GetStationPrizeLocks returns the prize locked for each station
+func (r *RedisRepository) GetStationPrizeLocks(ctx context.Context) (map[string]string, error) {
+	locks, err := r.client.HGetAll(ctx, r.key(stationLockKey)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get station locks: %w", err)
+	}
+	return locks, nil
+}
+
+// SetStationPrizeLock sets the prize for a station's next spin
+func (r *RedisRepository) SetStationPrizeLock(ctx context.Context, stationID, prizeID string) error {
+	return r.client.HSet(ctx, r.key(stationLockKey), stationID, prizeID).Err()
+}
+
+// DeleteStationPrizeLock removes a station's lock
+func (r *RedisRepository) DeleteStationPrizeLock(ctx context.Context, stationID string) error {
+	return r.client.HDel(ctx, r.key(stationLockKey), stationID).Err()
+}
+
+// GetNextPrizeLock checks if there's a global locked prize for the next spin
 func (r *RedisRepository) GetNextPrizeLock(ctx context.Context) (string, error) {
-	result, err := r.client.Get(ctx, lockKey).Result()
+	result, err := r.client.Get(ctx, r.key(lockKey)).Result()
 	if err == redis.Nil {
 		return "", nil
 	}
@@ -99,18 +227,18 @@ func (r *RedisRepository) GetNextPrizeLock(ctx context.Context) (string, error)
 
 // SetNextPrizeLock sets the prize for the next spin
 func (r *RedisRepository) SetNextPrizeLock(ctx context.Context, prizeID string) error {
-	return r.client.Set(ctx, lockKey, prizeID, 0).Err()
+	return r.client.Set(ctx, r.key(lockKey), prizeID, 0).Err()
 }
 
 // DeleteNextPrizeLock removes the lock after it's been used
 func (r *RedisRepository) DeleteNextPrizeLock(ctx context.Context) error {
-	return r.client.Del(ctx, lockKey).Err()
+	return r.client.Del(ctx, r.key(lockKey)).Err()
 }
 
 // DecrStock atomically decrements stock and returns the new value
 // Returns -1 if key doesn't exist (unlimited stock)
 func (r *RedisRepository) DecrStock(ctx context.Context, prizeID string) (int64, error) {
-	key := stockKeyPrefix + prizeID
+	key := r.key(stockKeyPrefix + prizeID)
 
 	// Check if key exists first
 	exists, err := r.client.Exists(ctx, key).Result()
@@ -133,14 +261,34 @@ func (r *RedisRepository) DecrStock(ctx context.Context, prizeID string) (int64,
 }
 
 // IncrStock atomically increments stock (used to restore stock if needed)
-func (r *RedisRepository) IncrStock(ctx context.Context, prizeID string) error {
-	key := stockKeyPrefix + prizeID
-	return r.client.Incr(ctx, key).Err()
+// and returns the new value
+func (r *RedisRepository) IncrStock(ctx context.Context, prizeID string) (int64, error) {
+	key := r.key(stockKeyPrefix + prizeID)
+	return r.client.Incr(ctx, key).Result()
+}
+
+// SeedStock sets a prize's stock only if it has no counter yet.
+// Returns false if the counter already existed.
+func (r *RedisRepository) SeedStock(ctx context.Context, prizeID string, stock int) (bool, error) {
+	seeded, err := r.client.SetNX(ctx, r.key(stockKeyPrefix+prizeID), stock, 0).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to seed stock for %s: %w", prizeID, err)
+	}
+	return seeded, nil
+}
+
+// StockExists reports whether a prize has a stock counter
+func (r *RedisRepository) StockExists(ctx context.Context, prizeID string) (bool, error) {
+	exists, err := r.client.Exists(ctx, r.key(stockKeyPrefix+prizeID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check stock existence: %w", err)
+	}
+	return exists == 1, nil
 }
 
 // GetStock returns current stock for a prize
 func (r *RedisRepository) GetStock(ctx context.Context, prizeID string) (int, error) {
-	key := stockKeyPrefix + prizeID
+	key := r.key(stockKeyPrefix + prizeID)
 
 	result, err := r.client.Get(ctx, key).Result()
 	if err == redis.Nil {
@@ -179,3 +327,499 @@ func (r *RedisRepository) GetAllStocks(ctx context.Context) ([]map[string]interf
 
 	return stocks, nil
 }
+
+// adjustStockScript sets (ARGV[1] = "set") or adds to (ARGV[1] = "add") a stock
+// counter and returns {before, after}. The counter is left unchanged if the
+// result would be negative.
+var adjustStockScript = redis.NewScript(`
+	local before = tonumber(redis.call("GET", KEYS[1]) or "0")
+	local after = tonumber(ARGV[2])
+	if ARGV[1] == "add" then
+		after = before + after
+	end
+	if after >= 0 then
+		redis.call("SET", KEYS[1], after)
+	end
+	return {before, after}
+`)
+
+// AdjustStock atomically sets a prize's stock to value, or adds value to it
+// when delta is true. Returns the stock before and after; if after is negative
+// nothing was changed.
+func (r *RedisRepository) AdjustStock(ctx context.Context, prizeID string, value int, delta bool) (before, after int, err error) {
+	mode := "set"
+	if delta {
+		mode = "add"
+	}
+
+	result, err := adjustStockScript.Run(ctx, r.client, []string{r.key(stockKeyPrefix + prizeID)}, mode, value).Int64Slice()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to adjust stock: %w", err)
+	}
+
+	return int(result[0]), int(result[1]), nil
+}
+
+// IncrLoginFailures counts a failed admin login for a scope and returns the
+// number of failures within the window. The window starts at the first failure.
+func (r *RedisRepository) IncrLoginFailures(ctx context.Context, scope string, window time.Duration) (int64, error) {
+	key := authFailKeyPrefix + scope
+
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.ExpireNX(ctx, key, window)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	return incr.Val(), nil
+}
+
+// ClearLoginFailures forgets the failed admin logins for a scope
+func (r *RedisRepository) ClearLoginFailures(ctx context.Context, scope string) error {
+	return r.client.Del(ctx, authFailKeyPrefix+scope).Err()
+}
+
+// IncrLockoutStrikes counts how many times a scope has been locked out
+// recently, which drives the exponential lockout duration
+func (r *RedisRepository) IncrLockoutStrikes(ctx context.Context, scope string, ttl time.Duration) (int64, error) {
+	key := authStrikeKeyPrefix + scope
+
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to record lockout: %w", err)
+	}
+
+	return incr.Val(), nil
+}
+
+// SetLoginLockout blocks admin logins for a scope for the given duration
+func (r *RedisRepository) SetLoginLockout(ctx context.Context, scope string, duration time.Duration) error {
+	return r.client.Set(ctx, authLockoutKeyPrefix+scope, 1, duration).Err()
+}
+
+// GetLoginLockout returns the remaining lockout for a scope, or 0 if not locked out
+func (r *RedisRepository) GetLoginLockout(ctx context.Context, scope string) (time.Duration, error) {
+	ttl, err := r.client.PTTL(ctx, authLockoutKeyPrefix+scope).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get lockout: %w", err)
+	}
+
+	// PTTL returns negative values when the key is missing or has no expiry
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+// SetSession stores an admin session under the hash of its token
+func (r *RedisRepository) SetSession(ctx context.Context, tokenHash string, principal models.AdminPrincipal, ttl time.Duration) error {
+	data, err := json.Marshal(principal)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	return r.client.Set(ctx, sessionKeyPrefix+tokenHash, data, ttl).Err()
+}
+
+// GetSession returns the admin session for a token hash, or nil if it has expired
+func (r *RedisRepository) GetSession(ctx context.Context, tokenHash string) (*models.AdminPrincipal, error) {
+	data, err := r.client.Get(ctx, sessionKeyPrefix+tokenHash).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	var principal models.AdminPrincipal
+	if err := json.Unmarshal(data, &principal); err != nil {
+		return nil, fmt.Errorf("invalid session: %w", err)
+	}
+	return &principal, nil
+}
+
+// DeleteSession ends an admin session
+func (r *RedisRepository) DeleteSession(ctx context.Context, tokenHash string) error {
+	return r.client.Del(ctx, sessionKeyPrefix+tokenHash).Err()
+}
+
+// MarkTOTPStepUsed records that a user consumed a TOTP time step.
+// Returns false if the step was already used, so a code cannot be replayed.
+func (r *RedisRepository) MarkTOTPStepUsed(ctx context.Context, username string, step int64, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("%s%s:%d", totpUsedKeyPrefix, username, step)
+	ok, err := r.client.SetNX(ctx, key, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark TOTP step: %w", err)
+	}
+	return ok, nil
+}
+
+// ClaimNonce records a request nonce for a station.
+// Returns false if the nonce was already used within the TTL.
+func (r *RedisRepository) ClaimNonce(ctx context.Context, stationID, nonce string, ttl time.Duration) (bool, error) {
+	ok, err := r.client.SetNX(ctx, nonceKeyPrefix+stationID+":"+nonce, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim nonce: %w", err)
+	}
+	return ok, nil
+}
+
+// ReserveIdempotencyKey claims an idempotency key before the work is done.
+// Returns false if the key was already claimed within the TTL.
+func (r *RedisRepository) ReserveIdempotencyKey(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	ok, err := r.client.SetNX(ctx, r.key(idempotencyKeyPrefix+key), idempotencyPending, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	return ok, nil
+}
+
+// GetIdempotentResult returns the stored result for an idempotency key.
+// Returns pending=true if the first request is still running, or nil data if the key is unknown.
+func (r *RedisRepository) GetIdempotentResult(ctx context.Context, key string) (data []byte, pending bool, err error) {
+	data, err = r.client.Get(ctx, r.key(idempotencyKeyPrefix+key)).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get idempotent result: %w", err)
+	}
+	if string(data) == idempotencyPending {
+		return nil, true, nil
+	}
+	return data, false, nil
+}
+
+// StoreIdempotentResult saves the result for a reserved idempotency key
+func (r *RedisRepository) StoreIdempotentResult(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, r.key(idempotencyKeyPrefix+key), data, ttl).Err()
+}
+
+// ReleaseIdempotencyKey forgets a reservation so a failed request can be retried
+func (r *RedisRepository) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.key(idempotencyKeyPrefix+key)).Err()
+}
+
+// PublishLiveEvent appends an event to a feed and notifies subscribers.
+// Returns the event with its stream ID.
+func (r *RedisRepository) PublishLiveEvent(ctx context.Context, feed, eventType string, data json.RawMessage) (*models.LiveEvent, error) {
+	id, err := r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: feed,
+		MaxLen: liveStreamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"type": eventType, "data": string(data)},
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to append live event: %w", err)
+	}
+
+	event := &models.LiveEvent{ID: id, Type: eventType, Data: data}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode live event: %w", err)
+	}
+	if err := r.client.Publish(ctx, feed, payload).Err(); err != nil {
+		return nil, fmt.Errorf("failed to publish live event: %w", err)
+	}
+
+	return event, nil
+}
+
+// GetLiveEventsSince returns a feed's events after lastID, oldest first.
+// Events older than the capped stream are gone.
+func (r *RedisRepository) GetLiveEventsSince(ctx context.Context, feed, lastID string) ([]models.LiveEvent, error) {
+	messages, err := r.client.XRange(ctx, feed, lastID, "+").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read live events: %w", err)
+	}
+
+	events := make([]models.LiveEvent, 0, len(messages))
+	for _, message := range messages {
+		if message.ID == lastID {
+			continue
+		}
+		eventType, _ := message.Values["type"].(string)
+		data, _ := message.Values["data"].(string)
+		events = append(events, models.LiveEvent{ID: message.ID, Type: eventType, Data: json.RawMessage(data)})
+	}
+
+	return events, nil
+}
+
+// GetRecentLiveEvents returns a feed's last n events, oldest first
+func (r *RedisRepository) GetRecentLiveEvents(ctx context.Context, feed string, n int64) ([]models.LiveEvent, error) {
+	messages, err := r.client.XRevRangeN(ctx, feed, "+", "-", n).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read live events: %w", err)
+	}
+
+	events := make([]models.LiveEvent, len(messages))
+	for i, message := range messages {
+		eventType, _ := message.Values["type"].(string)
+		data, _ := message.Values["data"].(string)
+		events[len(messages)-1-i] = models.LiveEvent{ID: message.ID, Type: eventType, Data: json.RawMessage(data)}
+	}
+
+	return events, nil
+}
+
+// SubscribeLiveEvents delivers a feed's events published by any instance
+// until ctx is cancelled. The channel is closed if the subscriber falls behind.
+func (r *RedisRepository) SubscribeLiveEvents(ctx context.Context, feed string) (<-chan models.LiveEvent, error) {
+	pubsub := r.client.Subscribe(ctx, feed)
+	// Wait for the subscription so no event published after this returns is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to live events: %w", err)
+	}
+
+	events := make(chan models.LiveEvent, 64)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event models.LiveEvent
+				if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+					continue
+				}
+				select {
+				case events <- event:
+				default:
+					// Too slow to keep up; closing makes the client reconnect and catch up with Last-Event-ID
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// SetBoothPause pauses spinning until the pause's ResumeAt, or until deleted
+func (r *RedisRepository) SetBoothPause(ctx context.Context, pause models.BoothPause) error {
+	data, err := json.Marshal(pause)
+	if err != nil {
+		return err
+	}
+
+	var ttl time.Duration
+	if pause.ResumeAt != nil {
+		ttl = time.Until(*pause.ResumeAt)
+	}
+	return r.client.Set(ctx, r.eventKey(boothPauseKey), data, ttl).Err()
+}
+
+// GetBoothPause returns the current pause, or nil if spinning is not paused
+func (r *RedisRepository) GetBoothPause(ctx context.Context) (*models.BoothPause, error) {
+	data, err := r.client.Get(ctx, r.eventKey(boothPauseKey)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booth pause: %w", err)
+	}
+
+	var pause models.BoothPause
+	if err := json.Unmarshal(data, &pause); err != nil {
+		return nil, fmt.Errorf("invalid booth pause: %w", err)
+	}
+	return &pause, nil
+}
+
+// DeleteBoothPause resumes spinning
+func (r *RedisRepository) DeleteBoothPause(ctx context.Context) error {
+	return r.client.Del(ctx, r.eventKey(boothPauseKey)).Err()
+}
+
+// GetEventOverride returns the opening hours override, or "" to follow the calendar
+func (r *RedisRepository) GetEventOverride(ctx context.Context) (string, error) {
+	override, err := r.client.Get(ctx, r.eventKey(eventOverrideKey)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get event override: %w", err)
+	}
+	return override, nil
+}
+
+// SetEventOverride sets the opening hours override; "" clears it
+func (r *RedisRepository) SetEventOverride(ctx context.Context, override string) error {
+	if override == "" {
+		return r.client.Del(ctx, r.eventKey(eventOverrideKey)).Err()
+	}
+	return r.client.Set(ctx, r.eventKey(eventOverrideKey), override, 0).Err()
+}
+
+// SetDisplayPaused pauses or resumes winner announcements
+func (r *RedisRepository) SetDisplayPaused(ctx context.Context, paused bool) error {
+	if paused {
+		return r.client.Set(ctx, r.key(displayPausedKey), 1, 0).Err()
+	}
+	return r.client.Del(ctx, r.key(displayPausedKey)).Err()
+}
+
+// IsDisplayPaused reports whether winner announcements are paused
+func (r *RedisRepository) IsDisplayPaused(ctx context.Context) (bool, error) {
+	n, err := r.client.Exists(ctx, r.key(displayPausedKey)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to get display pause: %w", err)
+	}
+	return n > 0, nil
+}
+
+// PublishKioskCommand sends a command to whichever backend instance holds the kiosk's connection
+func (r *RedisRepository) PublishKioskCommand(ctx context.Context, cmd models.KioskCommand) error {
+	return r.publishJSON(ctx, kioskCommandChannel, cmd)
+}
+
+// PublishKioskAck sends a kiosk's acknowledgement back to the instance waiting for it
+func (r *RedisRepository) PublishKioskAck(ctx context.Context, ack models.KioskAck) error {
+	return r.publishJSON(ctx, kioskAckChannel, ack)
+}
+
+// SubscribeKioskCommands returns kiosk commands published by any instance until ctx is cancelled
+func (r *RedisRepository) SubscribeKioskCommands(ctx context.Context) (<-chan models.KioskCommand, error) {
+	return subscribeJSON[models.KioskCommand](ctx, r.client, kioskCommandChannel)
+}
+
+// SubscribeKioskAcks returns kiosk acknowledgements received by any instance until ctx is cancelled
+func (r *RedisRepository) SubscribeKioskAcks(ctx context.Context) (<-chan models.KioskAck, error) {
+	return subscribeJSON[models.KioskAck](ctx, r.client, kioskAckChannel)
+}
+
+// TouchKiosk marks a kiosk as online now
+func (r *RedisRepository) TouchKiosk(ctx context.Context, stationID string) error {
+	return r.client.ZAdd(ctx, kioskOnlineKey, redis.Z{
+		Score:  float64(time.Now().Unix()),
+		Member: stationID,
+	}).Err()
+}
+
+// RemoveKiosk marks a kiosk as offline
+func (r *RedisRepository) RemoveKiosk(ctx context.Context, stationID string) error {
+	return r.client.ZRem(ctx, kioskOnlineKey, stationID).Err()
+}
+
+// GetOnlineKiosks returns the kiosks seen since the given time, dropping older entries
+func (r *RedisRepository) GetOnlineKiosks(ctx context.Context, since time.Time) ([]models.KioskPresence, error) {
+	cutoff := strconv.FormatInt(since.Unix(), 10)
+	if err := r.client.ZRemRangeByScore(ctx, kioskOnlineKey, "-inf", "("+cutoff).Err(); err != nil {
+		return nil, err
+	}
+
+	entries, err := r.client.ZRangeByScoreWithScores(ctx, kioskOnlineKey, &redis.ZRangeBy{Min: cutoff, Max: "+inf"}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	kiosks := make([]models.KioskPresence, 0, len(entries))
+	for _, entry := range entries {
+		kiosks = append(kiosks, models.KioskPresence{
+			StationID: entry.Member.(string),
+			LastSeen:  time.Unix(int64(entry.Score), 0),
+		})
+	}
+	return kiosks, nil
+}
+
+// SetKioskCampaign records the campaign a kiosk spins
+func (r *RedisRepository) SetKioskCampaign(ctx context.Context, stationID, campaignID string) error {
+	return r.client.HSet(ctx, kioskCampaignsKey, stationID, campaignID).Err()
+}
+
+// GetKioskCampaign returns the campaign a kiosk spins, or "" if it never said
+func (r *RedisRepository) GetKioskCampaign(ctx context.Context, stationID string) (string, error) {
+	campaignID, err := r.client.HGet(ctx, kioskCampaignsKey, stationID).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return campaignID, err
+}
+
+// IsKioskOnline reports whether a kiosk has been seen since the given time
+func (r *RedisRepository) IsKioskOnline(ctx context.Context, stationID string, since time.Time) (bool, error) {
+	score, err := r.client.ZScore(ctx, kioskOnlineKey, stationID).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return int64(score) >= since.Unix(), nil
+}
+
+// PublishEventStarted tells every instance that a new event is current
+func (r *RedisRepository) PublishEventStarted(ctx context.Context, event models.Event) error {
+	return r.publishJSON(ctx, eventStartedChannel, event)
+}
+
+// SubscribeEventStarted returns events started on any instance until ctx is cancelled
+func (r *RedisRepository) SubscribeEventStarted(ctx context.Context) (<-chan models.Event, error) {
+	return subscribeJSON[models.Event](ctx, r.client, eventStartedChannel)
+}
+
+func (r *RedisRepository) publishJSON(ctx context.Context, channel string, v interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return r.client.Publish(ctx, channel, payload).Err()
+}
+
+// subscribeJSON decodes the messages on a pub/sub channel until ctx is cancelled.
+// Unlike the live feed, a slow reader blocks rather than drops messages.
+func subscribeJSON[T any](ctx context.Context, client *redis.Client, channel string) (<-chan T, error) {
+	pubsub := client.Subscribe(ctx, channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", channel, err)
+	}
+
+	values := make(chan T, 64)
+	go func() {
+		defer close(values)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				var v T
+				if err := json.Unmarshal([]byte(message.Payload), &v); err != nil {
+					continue
+				}
+				select {
+				case values <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return values, nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"sync/atomic"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
+)
+
+// EventScope holds the ID of the event (fair) the booth is running. Every
+// repository shares one scope, so starting a new event moves stock, locks and
+// spin logs to it at once. The default event keeps the unprefixed Redis keys.
+type EventScope struct {
+	id atomic.Pointer[string]
+}
+
+// NewEventScope creates a scope set to the default event
+func NewEventScope() *EventScope {
+	scope := &EventScope{}
+	scope.Set(config.DefaultEventID)
+	return scope
+}
+
+// ID returns the current event ID
+func (s *EventScope) ID() string {
+	return *s.id.Load()
+}
+
+// Set switches to another event
+func (s *EventScope) Set(eventID string) {
+	s.id.Store(&eventID)
+}
+
+// keyPrefix is prepended to the current event's Redis keys
+func (s *EventScope) keyPrefix() string {
+	id := s.ID()
+	if id == config.DefaultEventID {
+		return ""
+	}
+	return "event:" + id + ":"
+}
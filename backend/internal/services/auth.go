@@ -0,0 +1,445 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// globalScope counts failed logins across every client
+	globalScope = "global"
+
+	// lockoutStrikeTTL is how long past lockouts count towards the next one
+	lockoutStrikeTTL = 24 * time.Hour
+
+	// secretUsername identifies actions performed with the shared ADMIN_SECRET
+	secretUsername = "admin_secret"
+)
+
+var (
+	ErrInvalidCredentials     = errors.New("invalid credentials")
+	ErrInvalidSession         = errors.New("session expired or invalid")
+	ErrTOTPRequired           = errors.New("two-factor code required")
+	ErrTOTPEnrollmentRequired = errors.New("two-factor enrollment required for this role")
+	ErrTOTPNotPending         = errors.New("no pending two-factor enrollment")
+	ErrTOTPAlreadyEnabled     = errors.New("two-factor is already enabled")
+	ErrUserExists             = errors.New("username already exists")
+	ErrInvalidRole            = errors.New("invalid role")
+)
+
+// LockoutError is returned when admin logins are temporarily blocked
+type LockoutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("too many failed attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// AuthService authenticates admin users and guards credentials against brute-force guessing
+type AuthService struct {
+	config   *config.Config
+	redis    *repository.RedisRepository
+	postgres *repository.PostgresRepository
+}
+
+// NewAuthService creates a new auth service
+func NewAuthService(cfg *config.Config, redis *repository.RedisRepository, postgres *repository.PostgresRepository) *AuthService {
+	return &AuthService{
+		config:   cfg,
+		redis:    redis,
+		postgres: postgres,
+	}
+}
+
+// EnsureAdminUser creates the bootstrap manager account from config if it does not exist yet
+func (s *AuthService) EnsureAdminUser(ctx context.Context) error {
+	if s.config.AdminUsername == "" || s.config.AdminPassword == "" {
+		return nil
+	}
+
+	existing, err := s.postgres.GetAdminUser(ctx, s.config.AdminUsername)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+
+	return s.CreateUser(ctx, s.config.AdminUsername, s.config.AdminPassword, models.RoleManager)
+}
+
+// CreateUser adds an admin account with a bcrypt-hashed password
+func (s *AuthService) CreateUser(ctx context.Context, username, password, role string) error {
+	if !models.IsValidRole(role) {
+		return ErrInvalidRole
+	}
+
+	existing, err := s.postgres.GetAdminUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return ErrUserExists
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return s.postgres.CreateAdminUser(ctx, models.AdminUser{
+		Username:     username,
+		PasswordHash: string(hash),
+		Role:         role,
+	})
+}
+
+// Authenticate resolves the caller of an admin request from a session token,
+// falling back to the shared admin secret, which has the staff role
+func (s *AuthService) Authenticate(ctx context.Context, ip, token, secret string) (*models.AdminPrincipal, error) {
+	if token != "" {
+		principal, err := s.redis.GetSession(ctx, hashToken(token))
+		if err != nil {
+			return nil, err
+		}
+		if principal == nil {
+			return nil, ErrInvalidSession
+		}
+		return principal, nil
+	}
+
+	// The shared secret is only ever staff: manager actions need a TOTP login
+	if s.config.AdminSecretRole != models.RoleStaff {
+		return nil, ErrInvalidCredentials
+	}
+
+	ok, err := s.attempt(ctx, ip, func() (bool, error) {
+		return subtle.ConstantTimeCompare([]byte(secret), []byte(s.config.AdminSecret)) == 1, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &models.AdminPrincipal{Username: secretUsername, Role: models.RoleStaff}, nil
+}
+
+// LoginWithSecret starts a session for the shared admin secret
+func (s *AuthService) LoginWithSecret(ctx context.Context, ip, secret string) (*models.AdminSession, error) {
+	principal, err := s.Authenticate(ctx, ip, "", secret)
+	if err != nil {
+		return nil, err
+	}
+	return s.startSession(ctx, *principal)
+}
+
+// Login starts a session for an admin user. Users with TOTP enabled, and every
+// user whose role can reset stock or lock limited prizes, must also pass a TOTP
+// or recovery code.
+func (s *AuthService) Login(ctx context.Context, ip, username, password, code string) (*models.AdminSession, error) {
+	var user *models.AdminUser
+	ok, err := s.attempt(ctx, ip, func() (bool, error) {
+		u, ok, err := s.checkPassword(ctx, username, password)
+		if err != nil || !ok {
+			return ok, err
+		}
+
+		if u.TOTPEnabled || models.RoleAtLeast(u.Role, models.RoleManager) {
+			if !u.TOTPEnabled {
+				return false, ErrTOTPEnrollmentRequired
+			}
+			if code == "" {
+				return false, ErrTOTPRequired
+			}
+			if ok, err := s.checkSecondFactor(ctx, u, code); err != nil || !ok {
+				return ok, err
+			}
+		}
+
+		user = u
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	s.postgres.LogAuditAsync(models.AuditEntry{
+		Action:    "login",
+		IPAddress: ip,
+		Detail:    fmt.Sprintf("user=%s role=%s", user.Username, user.Role),
+		Timestamp: time.Now(),
+	})
+
+	return s.startSession(ctx, models.AdminPrincipal{Username: user.Username, Role: user.Role})
+}
+
+// Logout ends a session
+func (s *AuthService) Logout(ctx context.Context, token string) error {
+	return s.redis.DeleteSession(ctx, hashToken(token))
+}
+
+// BeginTOTPEnrollment generates a new TOTP secret and recovery codes for a user.
+// TOTP is not enforced until ConfirmTOTPEnrollment succeeds.
+func (s *AuthService) BeginTOTPEnrollment(ctx context.Context, ip, username, password string) (*models.TOTPEnrollment, error) {
+	user, err := s.authenticateUser(ctx, ip, username, password)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		// Re-enrolling would let a stolen password replace the second factor
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	codes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = hashRecoveryCode(code)
+	}
+	if err := s.postgres.SetTOTPSecret(ctx, user.ID, secret, hashes); err != nil {
+		return nil, err
+	}
+
+	return &models.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totpProvisioningURI(s.config.TOTPIssuer, user.Username, secret),
+		RecoveryCodes:   codes,
+	}, nil
+}
+
+// ConfirmTOTPEnrollment enables TOTP once the user proves their authenticator works
+func (s *AuthService) ConfirmTOTPEnrollment(ctx context.Context, ip, username, password, code string) error {
+	user, err := s.authenticateUser(ctx, ip, username, password)
+	if err != nil {
+		return err
+	}
+	if user.TOTPEnabled || user.TOTPSecret == "" {
+		return ErrTOTPNotPending
+	}
+
+	ok, err := s.attempt(ctx, ip, func() (bool, error) {
+		step, ok := verifyTOTP(user.TOTPSecret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+		return s.redis.MarkTOTPStepUsed(ctx, user.Username, step, totpReplayTTL())
+	})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidCredentials
+	}
+
+	if err := s.postgres.EnableTOTP(ctx, user.ID); err != nil {
+		return err
+	}
+
+	s.postgres.LogAuditAsync(models.AuditEntry{
+		Action:    "totp_enabled",
+		IPAddress: ip,
+		Detail:    "user=" + user.Username,
+		Timestamp: time.Now(),
+	})
+
+	return nil
+}
+
+// authenticateUser checks a username and password through the brute-force guard
+func (s *AuthService) authenticateUser(ctx context.Context, ip, username, password string) (*models.AdminUser, error) {
+	var user *models.AdminUser
+	ok, err := s.attempt(ctx, ip, func() (bool, error) {
+		u, ok, err := s.checkPassword(ctx, username, password)
+		user = u
+		return ok, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// checkPassword looks up a user and compares the password against its bcrypt hash
+func (s *AuthService) checkPassword(ctx context.Context, username, password string) (*models.AdminUser, bool, error) {
+	user, err := s.postgres.GetAdminUser(ctx, username)
+	if err != nil {
+		return nil, false, err
+	}
+	if user == nil {
+		return nil, false, nil
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, false, nil
+	}
+	return user, true, nil
+}
+
+// checkSecondFactor accepts either a fresh TOTP code or an unused recovery code
+func (s *AuthService) checkSecondFactor(ctx context.Context, user *models.AdminUser, code string) (bool, error) {
+	if step, ok := verifyTOTP(user.TOTPSecret, code, time.Now()); ok {
+		return s.redis.MarkTOTPStepUsed(ctx, user.Username, step, totpReplayTTL())
+	}
+
+	used, err := s.postgres.UseRecoveryCode(ctx, user.ID, hashRecoveryCode(code))
+	if err != nil {
+		return false, err
+	}
+	if used {
+		s.postgres.LogAuditAsync(models.AuditEntry{
+			Action:    "recovery_code_used",
+			Detail:    "user=" + user.Username,
+			Timestamp: time.Now(),
+		})
+	}
+	return used, nil
+}
+
+// startSession issues a random session token and stores only its hash
+func (s *AuthService) startSession(ctx context.Context, principal models.AdminPrincipal) (*models.AdminSession, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate session token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	if err := s.redis.SetSession(ctx, hashToken(token), principal, s.config.AdminSessionTTL); err != nil {
+		return nil, err
+	}
+
+	return &models.AdminSession{
+		Token:     token,
+		Username:  principal.Username,
+		Role:      principal.Role,
+		ExpiresAt: time.Now().Add(s.config.AdminSessionTTL),
+	}, nil
+}
+
+// attempt runs a credential check for a client IP, enforcing lockouts.
+// A check returning (false, nil) counts as a failed attempt; errors are passed through uncounted.
+// Returns a *LockoutError while the IP (or every client) is locked out.
+func (s *AuthService) attempt(ctx context.Context, ip string, check func() (bool, error)) (bool, error) {
+	ipScope := "ip:" + ip
+
+	// Step 1: Refuse outright while locked out, without counting the attempt
+	if err := s.checkLockout(ctx, ipScope); err != nil {
+		return false, err
+	}
+
+	// Step 2: Run the check
+	ok, err := check()
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		s.redis.ClearLoginFailures(ctx, ipScope)
+		return true, nil
+	}
+
+	// Step 3: Count the failure per IP and globally, locking out when over the limit
+	ipFailures, err := s.redis.IncrLoginFailures(ctx, ipScope, s.config.LoginAttemptWindow)
+	if err != nil {
+		return false, err
+	}
+	globalFailures, err := s.redis.IncrLoginFailures(ctx, globalScope, s.config.LoginAttemptWindow)
+	if err != nil {
+		return false, err
+	}
+
+	if globalFailures >= int64(s.config.LoginGlobalMaxAttempts) {
+		return false, s.lockout(ctx, globalScope, ip, globalFailures)
+	}
+	if ipFailures >= int64(s.config.LoginMaxAttempts) {
+		return false, s.lockout(ctx, ipScope, ip, ipFailures)
+	}
+
+	return false, nil
+}
+
+// checkLockout returns a *LockoutError if the IP scope or the global scope is locked out
+func (s *AuthService) checkLockout(ctx context.Context, ipScope string) error {
+	var retryAfter time.Duration
+	for _, scope := range []string{ipScope, globalScope} {
+		remaining, err := s.redis.GetLoginLockout(ctx, scope)
+		if err != nil {
+			return err
+		}
+		if remaining > retryAfter {
+			retryAfter = remaining
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LockoutError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// lockout blocks a scope, doubling the duration for every recent lockout
+func (s *AuthService) lockout(ctx context.Context, scope, ip string, failures int64) error {
+	strikes, err := s.redis.IncrLockoutStrikes(ctx, scope, lockoutStrikeTTL)
+	if err != nil {
+		return err
+	}
+
+	duration := s.config.LoginLockoutBase
+	for i := int64(1); i < strikes && duration < s.config.LoginLockoutMax; i++ {
+		duration *= 2
+	}
+	if duration > s.config.LoginLockoutMax {
+		duration = s.config.LoginLockoutMax
+	}
+
+	if err := s.redis.SetLoginLockout(ctx, scope, duration); err != nil {
+		return err
+	}
+	s.redis.ClearLoginFailures(ctx, scope)
+
+	s.postgres.LogAuditAsync(models.AuditEntry{
+		Action:    "login_lockout",
+		IPAddress: ip,
+		Detail:    fmt.Sprintf("scope=%s failures=%d strike=%d duration=%s", scope, failures, strikes, duration),
+		Timestamp: time.Now(),
+	})
+
+	return &LockoutError{RetryAfter: duration}
+}
+
+// hashToken hashes a session token so Redis never holds usable tokens
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
+
+// totpReplayTTL covers every step verifyTOTP can still accept
+func totpReplayTTL() time.Duration {
+	return totpPeriod * time.Duration(2*totpSkew+2)
+}
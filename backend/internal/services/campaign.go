@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/repository"
+)
+
+// CampaignService holds one lottery and one winner display per campaign. Each
+// campaign's lottery has its own prizes, stock counters, locks and spin logs,
+// and its display its own feed and pause; the booth pause, opening hours and
+// admin feed are shared.
+type CampaignService struct {
+	campaigns []config.Campaign
+	lotteries map[string]*LotteryService
+	displays  map[string]*DisplayService
+}
+
+// NewCampaignService creates a lottery and display for each configured campaign
+func NewCampaignService(cfg *config.Config, redis *repository.RedisRepository, postgres *repository.PostgresRepository, webhooks *WebhookService, live *LiveFeedService) *CampaignService {
+	s := &CampaignService{
+		campaigns: cfg.Campaigns,
+		lotteries: make(map[string]*LotteryService, len(cfg.Campaigns)),
+		displays:  make(map[string]*DisplayService, len(cfg.Campaigns)),
+	}
+	for _, campaign := range cfg.Campaigns {
+		scoped := cfg.ForCampaign(campaign)
+		scopedRedis := redis.ForCampaign(scoped, campaign.ID)
+		feed := NewLiveFeedService(scopedRedis, scopedRedis.Feed(repository.DisplayFeed), cfg.DisplayRecent)
+		display := NewDisplayService(scoped, scopedRedis, feed)
+		s.displays[campaign.ID] = display
+		s.lotteries[campaign.ID] = NewLotteryService(scoped, scopedRedis, postgres.ForCampaign(campaign.ID), webhooks, live, display)
+	}
+	return s
+}
+
+// Default returns the lottery of the default campaign
+func (s *CampaignService) Default() *LotteryService {
+	return s.lotteries[config.DefaultCampaignID]
+}
+
+// Display returns the winner display of a campaign
+func (s *CampaignService) Display(campaignID string) (*DisplayService, bool) {
+	display, ok := s.displays[campaignID]
+	return display, ok
+}
+
+// Get returns the lottery of a campaign
+func (s *CampaignService) Get(campaignID string) (*LotteryService, bool) {
+	lottery, ok := s.lotteries[campaignID]
+	return lottery, ok
+}
+
+// Campaigns returns the configured campaigns, the default first
+func (s *CampaignService) Campaigns() []config.Campaign {
+	return s.campaigns
+}
+
+// List returns the campaigns for kiosks to choose from
+func (s *CampaignService) List() []models.Campaign {
+	list := make([]models.Campaign, len(s.campaigns))
+	for i, campaign := range s.campaigns {
+		list[i] = models.Campaign{
+			ID:      campaign.ID,
+			Name:    campaign.Name,
+			Default: campaign.ID == config.DefaultCampaignID,
+		}
+	}
+	return list
+}
+
+// SeedStocks creates missing stock counters for every campaign
+func (s *CampaignService) SeedStocks(ctx context.Context) error {
+	for _, campaign := range s.campaigns {
+		if err := s.lotteries[campaign.ID].SeedStocks(ctx); err != nil {
+			return fmt.Errorf("campaign %s: %w", campaign.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/repository"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	// claimSignatureBytes is how much of the HMAC is kept, short enough for a small QR
+	claimSignatureBytes = 16
+
+	// claimQRSize is the width and height of the QR image in pixels
+	claimQRSize = 256
+)
+
+var (
+	ErrClaimInvalid  = errors.New("claim token is malformed or has an invalid signature")
+	ErrClaimExpired  = errors.New("claim token has expired")
+	ErrClaimNotFound = errors.New("claim not found")
+	ErrClaimUsed     = errors.New("prize has already been claimed")
+)
+
+// ClaimService signs prize pickup tokens and verifies them at the prize desk
+type ClaimService struct {
+	config   *config.Config
+	postgres *repository.PostgresRepository
+}
+
+// NewClaimService creates a new claim service
+func NewClaimService(cfg *config.Config, postgres *repository.PostgresRepository) *ClaimService {
+	return &ClaimService{
+		config:   cfg,
+		postgres: postgres,
+	}
+}
+
+// newClaim builds an unsaved claim for a winning spin
+func newClaim(cfg *config.Config, log models.SpinLog) (*models.Claim, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate claim ID: %w", err)
+	}
+
+	issuedAt := log.Timestamp.Truncate(time.Second)
+	return &models.Claim{
+		ID:        id,
+		PrizeID:   log.PrizeWon,
+		IssuedAt:  issuedAt,
+		ExpiresAt: issuedAt.Add(cfg.ClaimTTL),
+	}, nil
+}
+
+// signClaim returns the token for a saved claim:
+// spin ID, prize ID, issued and expiry Unix seconds and a truncated HMAC, joined by dots
+func signClaim(cfg *config.Config, claim *models.Claim) string {
+	payload := strings.Join([]string{
+		strconv.FormatInt(claim.SpinLogID, 10),
+		claim.PrizeID,
+		strconv.FormatInt(claim.IssuedAt.Unix(), 10),
+		strconv.FormatInt(claim.ExpiresAt.Unix(), 10),
+	}, ".")
+	return payload + "." + claimSignature(cfg, payload)
+}
+
+func claimSignature(cfg *config.Config, payload string) string {
+	mac := hmac.New(sha256.New, []byte(cfg.ClaimSecret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:claimSignatureBytes])
+}
+
+// parseClaimToken checks a token's signature and expiry as of now and
+// returns the spin it was issued for
+func parseClaimToken(cfg *config.Config, token string, now time.Time) (int64, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 5 {
+		return 0, ErrClaimInvalid
+	}
+	payload := strings.Join(parts[:4], ".")
+	if !hmac.Equal([]byte(claimSignature(cfg, payload)), []byte(parts[4])) {
+		return 0, ErrClaimInvalid
+	}
+
+	spinLogID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, ErrClaimInvalid
+	}
+	expiresAt, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return 0, ErrClaimInvalid
+	}
+	if now.After(time.Unix(expiresAt, 0)) {
+		return 0, ErrClaimExpired
+	}
+	return spinLogID, nil
+}
+
+// QRCode renders the claim token for a claim as a PNG
+func (s *ClaimService) QRCode(ctx context.Context, id string) ([]byte, error) {
+	claim, err := s.postgres.GetClaim(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if claim == nil {
+		return nil, ErrClaimNotFound
+	}
+
+	png, err := qrcode.Encode(signClaim(s.config, claim), qrcode.Medium, claimQRSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render QR code: %w", err)
+	}
+	return png, nil
+}
+
+// Verify checks a scanned claim token and marks the prize as handed over.
+// A token can only be claimed once; later scans return the claim with ErrClaimUsed.
+func (s *ClaimService) Verify(ctx context.Context, token, claimedBy string) (*models.Claim, error) {
+	// Step 1: Check the signature and expiry before touching the database
+	spinLogID, err := parseClaimToken(s.config, token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 2: Mark claimed, explaining why if it was refused
+	claimed, err := s.postgres.MarkClaimed(ctx, spinLogID, claimedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	claim, err := s.postgres.GetClaimBySpinLog(ctx, spinLogID)
+	if err != nil {
+		return nil, err
+	}
+	if claim == nil {
+		return nil, ErrClaimNotFound
+	}
+	if !claimed {
+		return claim, ErrClaimUsed
+	}
+	return claim, nil
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+)
+
+func TestParseClaimToken(t *testing.T) {
+	cfg := &config.Config{ClaimSecret: "claim-secret", ClaimTTL: time.Hour}
+	spunAt := time.Unix(1700000000, 0)
+	claim, err := newClaim(cfg, models.SpinLog{PrizeWon: "MK_DUCK", Timestamp: spunAt.Add(400 * time.Millisecond)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claim.SpinLogID = 42
+	token := signClaim(cfg, claim)
+
+	if !claim.IssuedAt.Equal(spunAt) || !claim.ExpiresAt.Equal(spunAt.Add(time.Hour)) {
+		t.Fatalf("newClaim() issued %v expires %v", claim.IssuedAt, claim.ExpiresAt)
+	}
+
+	// replacePart swaps one dot-separated field, keeping the original signature
+	replacePart := func(i int, value string) string {
+		parts := strings.Split(token, ".")
+		parts[i] = value
+		return strings.Join(parts, ".")
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		token  string
+		now    time.Time
+		want   error
+	}{
+		{"valid", "claim-secret", token, spunAt.Add(time.Minute), nil},
+		{"surrounding whitespace", "claim-secret", " " + token + "\n", spunAt, nil},
+		{"at expiry", "claim-secret", token, spunAt.Add(time.Hour), nil},
+		{"expired", "claim-secret", token, spunAt.Add(time.Hour + time.Second), ErrClaimExpired},
+		{"other spin", "claim-secret", replacePart(0, "43"), spunAt, ErrClaimInvalid},
+		{"other prize", "claim-secret", replacePart(1, "IPHONE"), spunAt, ErrClaimInvalid},
+		{"expiry extended", "claim-secret", replacePart(3, "1800000000"), spunAt, ErrClaimInvalid},
+		{"signature tampered", "claim-secret", replacePart(4, "AAAAAAAAAAAAAAAAAAAAAA"), spunAt, ErrClaimInvalid},
+		{"wrong secret", "other-secret", token, spunAt, ErrClaimInvalid},
+		{"missing part", "claim-secret", token[:strings.LastIndex(token, ".")], spunAt, ErrClaimInvalid},
+		{"empty", "claim-secret", "", spunAt, ErrClaimInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{ClaimSecret: tt.secret}
+			id, err := parseClaimToken(cfg, tt.token, tt.now)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("parseClaimToken() error = %v, want %v", err, tt.want)
+			}
+			if err == nil && id != 42 {
+				t.Errorf("parseClaimToken() = %d, want 42", id)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/repository"
+)
+
+// DisplayService announces wins of announce-worthy prizes on the big-screen display feed
+type DisplayService struct {
+	config *config.Config
+	redis  *repository.RedisRepository
+	feed   *LiveFeedService
+}
+
+// NewDisplayService creates a new display service
+func NewDisplayService(cfg *config.Config, redis *repository.RedisRepository, feed *LiveFeedService) *DisplayService {
+	return &DisplayService{
+		config: cfg,
+		redis:  redis,
+		feed:   feed,
+	}
+}
+
+// Feed returns the display feed announcements are published to
+func (s *DisplayService) Feed() *LiveFeedService {
+	return s.feed
+}
+
+// AnnounceAsync puts a win on the display feed, unless the prize is not
+// announced or announcements are paused
+func (s *DisplayService) AnnounceAsync(prize config.Prize, instagramID string, wonAt time.Time) {
+	if !prize.Announce {
+		return
+	}
+
+	go func() {
+		paused, err := s.redis.IsDisplayPaused(context.Background())
+		if err != nil {
+			fmt.Printf("Failed to announce winner: %v\n", err)
+			return
+		}
+		if paused {
+			return
+		}
+
+		handle := MaskInstagramID(instagramID, s.config.DisplayMaskPrefix, s.config.DisplayMaskSuffix)
+		name := prize.LocalizedName(config.DefaultLanguage)
+		text := "Someone won " + name
+		if handle != "" {
+			text = "@" + handle + " won " + name
+		}
+
+		s.feed.PublishAsync(models.EventWinnerAnnounced, models.Announcement{
+			InstagramID: handle,
+			PrizeID:     prize.ID,
+			PrizeName:   name,
+			Icon:        prize.Icon,
+			Text:        text,
+			WonAt:       wonAt,
+		})
+	}()
+}
+
+// SetPaused pauses or resumes announcements and tells connected displays
+func (s *DisplayService) SetPaused(ctx context.Context, paused bool) error {
+	if err := s.redis.SetDisplayPaused(ctx, paused); err != nil {
+		return err
+	}
+
+	eventType := models.EventDisplayResumed
+	if paused {
+		eventType = models.EventDisplayPaused
+	}
+	s.feed.PublishAsync(eventType, struct{}{})
+	return nil
+}
+
+// Status returns whether announcements are paused and which prizes are announced
+func (s *DisplayService) Status(ctx context.Context) (*models.DisplayStatus, error) {
+	paused, err := s.redis.IsDisplayPaused(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.DisplayStatus{Paused: paused, AnnouncedPrizes: []string{}}
+	for _, prize := range s.config.Prizes {
+		if prize.Announce {
+			status.AnnouncedPrizes = append(status.AnnouncedPrizes, prize.ID)
+		}
+	}
+	return status, nil
+}
+
+// MaskInstagramID hides the middle of a handle, keeping the first prefix and
+// last suffix characters: "chaiyawutar" becomes "ch***ar". Handles too short
+// to keep both ends show only their first character. The mask is always three
+// stars so the handle's length is not revealed.
+func MaskInstagramID(instagramID string, prefix, suffix int) string {
+	runes := []rune(strings.TrimPrefix(strings.TrimSpace(instagramID), "@"))
+	prefix, suffix = max(prefix, 0), max(suffix, 0)
+
+	switch {
+	case len(runes) == 0:
+		return ""
+	case len(runes) > prefix+suffix:
+		return string(runes[:prefix]) + "***" + string(runes[len(runes)-suffix:])
+	default:
+		return string(runes[:1]) + "***"
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/repository"
+)
+
+// eventResubscribeDelay is the wait before retrying a failed Redis subscription
+const eventResubscribeDelay = 5 * time.Second
+
+var (
+	ErrEventIDInvalid = errors.New("event id must be 1-50 lowercase letters, digits, '-' or '_'")
+	ErrEventExists    = errors.New("an event with this id already exists")
+)
+
+var eventIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// EventService tracks the event (fair) the booth is running. Starting a new
+// event archives the current one: its spin logs stay in Postgres, and the new
+// event gets fresh stock counters, locks and logs.
+type EventService struct {
+	redis     *repository.RedisRepository
+	postgres  *repository.PostgresRepository
+	scope     *repository.EventScope
+	campaigns *CampaignService
+	live      *LiveFeedService
+}
+
+// NewEventService creates a new event service
+func NewEventService(redis *repository.RedisRepository, postgres *repository.PostgresRepository, scope *repository.EventScope, campaigns *CampaignService, live *LiveFeedService) *EventService {
+	return &EventService{
+		redis:     redis,
+		postgres:  postgres,
+		scope:     scope,
+		campaigns: campaigns,
+		live:      live,
+	}
+}
+
+// Load switches to the current event stored in Postgres
+func (s *EventService) Load(ctx context.Context) error {
+	event, err := s.postgres.GetCurrentEvent(ctx)
+	if err != nil {
+		return err
+	}
+	if event != nil {
+		s.scope.Set(event.ID)
+	}
+	return nil
+}
+
+// List returns every event, newest first
+func (s *EventService) List(ctx context.Context) ([]models.Event, error) {
+	return s.postgres.ListEvents(ctx)
+}
+
+// Start archives the current event and starts a new one with the configured stock
+func (s *EventService) Start(ctx context.Context, id, name, actor string) (*models.EventStartedEvent, error) {
+	// Step 1: Validate the event
+	id, name = strings.TrimSpace(id), strings.TrimSpace(name)
+	if !eventIDPattern.MatchString(id) {
+		return nil, ErrEventIDInvalid
+	}
+	if name == "" {
+		name = id
+	}
+
+	// Step 2: Archive the current event and store the new one
+	event := models.Event{
+		ID:        id,
+		Name:      name,
+		StartedAt: time.Now(),
+		StartedBy: actor,
+	}
+	archived, err := s.postgres.StartEvent(ctx, event)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, ErrEventExists
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 3: Switch this and every other instance, and stock the new event
+	s.scope.Set(event.ID)
+	if err := s.redis.PublishEventStarted(ctx, event); err != nil {
+		fmt.Printf("Failed to announce new event: %v\n", err)
+	}
+	if err := s.campaigns.SeedStocks(ctx); err != nil {
+		return nil, fmt.Errorf("failed to seed stock: %w", err)
+	}
+
+	started := &models.EventStartedEvent{Event: event, Archived: archived}
+	s.live.PublishAsync(models.EventStarted, started)
+	return started, nil
+}
+
+// Run follows events started on other instances until ctx is cancelled
+func (s *EventService) Run(ctx context.Context) {
+	for {
+		if err := s.follow(ctx); err != nil {
+			fmt.Printf("Failed to follow event changes: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(eventResubscribeDelay):
+		}
+	}
+}
+
+// follow runs until the Redis subscription ends
+func (s *EventService) follow(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	started, err := s.redis.SubscribeEventStarted(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Catch up on an event started while not subscribed
+	if err := s.Load(ctx); err != nil {
+		return err
+	}
+
+	for event := range started {
+		s.scope.Set(event.ID)
+	}
+	return errors.New("event subscription closed")
+}
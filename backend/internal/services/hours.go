@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+)
+
+var (
+	ErrEventClosed          = errors.New("booth is closed")
+	ErrEventOverrideInvalid = errors.New("override must be open, closed or empty")
+)
+
+// EventStatus reports whether the booth is open now, following the event
+// calendar unless an admin has overridden it
+func (s *LotteryService) EventStatus(ctx context.Context) (*models.EventStatus, error) {
+	override, err := s.redis.GetEventOverride(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return eventStatusAt(s.config, override, config.GetCurrentTime()), nil
+}
+
+// eventStatusAt works out the booth status at now from the event calendar and
+// the admin override
+func eventStatusAt(cfg *config.Config, override string, now time.Time) *models.EventStatus {
+	status := &models.EventStatus{
+		Open:     len(cfg.EventHours) == 0,
+		Override: override,
+		Timezone: cfg.EventTimezone.String(),
+		Hours:    make([]models.EventHours, len(cfg.EventHours)),
+	}
+	for i, hours := range cfg.EventHours {
+		status.Hours[i] = models.EventHours{Opens: hours.Opens, Closes: hours.Closes}
+	}
+
+	for _, hours := range cfg.EventHours {
+		if !now.Before(hours.Opens) && now.Before(hours.Closes) {
+			closes := hours.Closes
+			status.Open = true
+			status.ClosesAt = &closes
+			break
+		}
+		if hours.Opens.After(now) {
+			opens := hours.Opens
+			status.OpensAt = &opens
+			break
+		}
+	}
+
+	switch override {
+	case models.EventOverrideOpen:
+		status.Open = true
+		status.OpensAt, status.ClosesAt = nil, nil
+	case models.EventOverrideClosed:
+		status.Open = false
+		status.OpensAt, status.ClosesAt = nil, nil
+	}
+	return status
+}
+
+// SetEventOverride forces the booth open or closed regardless of the calendar,
+// or with an empty override goes back to following it
+func (s *LotteryService) SetEventOverride(ctx context.Context, override, actor string) (*models.EventStatus, error) {
+	switch override {
+	case "", models.EventOverrideOpen, models.EventOverrideClosed:
+	default:
+		return nil, ErrEventOverrideInvalid
+	}
+
+	if err := s.redis.SetEventOverride(ctx, override); err != nil {
+		return nil, err
+	}
+
+	status, err := s.EventStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.live.PublishAsync(models.EventHoursOverridden, models.EventOverrideEvent{Override: override, Actor: actor, Open: status.Open})
+	return status, nil
+}
+
+// checkEventOpen returns ErrEventClosed outside opening hours
+func (s *LotteryService) checkEventOpen(ctx context.Context) error {
+	status, err := s.EventStatus(ctx)
+	if err != nil {
+		return err
+	}
+	if !status.Open {
+		return ErrEventClosed
+	}
+	return nil
+}
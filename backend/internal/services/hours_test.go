@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+)
+
+func TestEventStatusAt(t *testing.T) {
+	loc := time.FixedZone("ICT", 7*60*60)
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, time.January, day, hour, 0, 0, 0, loc)
+	}
+	cfg := &config.Config{
+		EventTimezone: loc,
+		EventHours: []config.EventHours{
+			{Opens: at(30, 10), Closes: at(30, 22)},
+			{Opens: at(31, 18), Closes: at(32, 2)}, // past midnight
+		},
+	}
+	always := &config.Config{EventTimezone: loc}
+
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		override string
+		now      time.Time
+		open     bool
+		opensAt  *time.Time
+		closesAt *time.Time
+	}{
+		{"no calendar", always, "", at(30, 3), true, nil, nil},
+		{"no calendar closed by override", always, models.EventOverrideClosed, at(30, 3), false, nil, nil},
+		{"before the event", cfg, "", at(29, 12), false, timePtr(at(30, 10)), nil},
+		{"at opening", cfg, "", at(30, 10), true, nil, timePtr(at(30, 22))},
+		{"at closing", cfg, "", at(30, 22), false, timePtr(at(31, 18)), nil},
+		{"after midnight", cfg, "", at(32, 1), true, nil, timePtr(at(32, 2))},
+		{"after the event", cfg, "", at(32, 3), false, nil, nil},
+		{"opened by override", cfg, models.EventOverrideOpen, at(29, 12), true, nil, nil},
+		{"closed by override", cfg, models.EventOverrideClosed, at(30, 12), false, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := eventStatusAt(tt.cfg, tt.override, tt.now)
+			if status.Open != tt.open {
+				t.Errorf("Open = %v, want %v", status.Open, tt.open)
+			}
+			if !sameTime(status.OpensAt, tt.opensAt) {
+				t.Errorf("OpensAt = %v, want %v", status.OpensAt, tt.opensAt)
+			}
+			if !sameTime(status.ClosesAt, tt.closesAt) {
+				t.Errorf("ClosesAt = %v, want %v", status.ClosesAt, tt.closesAt)
+			}
+			if status.Override != tt.override || len(status.Hours) != len(tt.cfg.EventHours) {
+				t.Errorf("calendar not carried over: %+v", status)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
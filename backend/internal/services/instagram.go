@@ -0,0 +1,40 @@
+package services
+
+import (
+	"errors"
+	"strings"
+)
+
+// maxInstagramIDLength is Instagram's limit on username length
+const maxInstagramIDLength = 30
+
+var (
+	ErrInstagramIDTooLong = errors.New("instagram_id must be at most 30 characters")
+	ErrInstagramIDInvalid = errors.New("instagram_id may only contain letters, numbers, periods and underscores, and cannot start or end with a period or have two in a row")
+)
+
+// NormalizeInstagramID returns the canonical form of a handle, so "@Foo",
+// "foo " and "FOO" are the same player: trimmed, without a leading "@" and
+// lowercased. It is validated against Instagram's username rules. An empty
+// handle is allowed, since giving one is optional.
+func NormalizeInstagramID(raw string) (string, error) {
+	id := strings.TrimSpace(raw)
+	id = strings.TrimSpace(strings.TrimPrefix(id, "@"))
+	id = strings.ToLower(id)
+
+	if id == "" {
+		return "", nil
+	}
+	if len(id) > maxInstagramIDLength {
+		return "", ErrInstagramIDTooLong
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '_') {
+			return "", ErrInstagramIDInvalid
+		}
+	}
+	if strings.HasPrefix(id, ".") || strings.HasSuffix(id, ".") || strings.Contains(id, "..") {
+		return "", ErrInstagramIDInvalid
+	}
+	return id, nil
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/repository"
+)
+
+const (
+	// KioskHeartbeat is how often a connected kiosk is pinged and marked online
+	KioskHeartbeat = 15 * time.Second
+
+	// kioskOnlineWindow is how long a kiosk counts as online after it was last seen
+	kioskOnlineWindow = 3 * KioskHeartbeat
+
+	// kioskAckTimeout is how long a command waits for the kiosk to acknowledge it
+	kioskAckTimeout = 5 * time.Second
+
+	// kioskResubscribeDelay is the wait before retrying a failed Redis subscription
+	kioskResubscribeDelay = 5 * time.Second
+
+	maxKioskMessageLength = 200
+)
+
+var (
+	ErrKioskCommandInvalid = errors.New("command type must be lock, pause, resume, message or spin")
+	ErrKioskMessageInvalid = errors.New("message is required and must be at most 200 characters")
+	ErrKioskOffline        = errors.New("kiosk is not connected")
+	ErrKioskAckTimeout     = errors.New("kiosk did not acknowledge the command in time")
+	ErrKioskCampaign       = errors.New("unknown campaign")
+)
+
+// KioskSocket is the WebSocket of one connected kiosk
+type KioskSocket interface {
+	WriteJSON(v interface{}) error
+	Close() error
+}
+
+// KioskHub tracks kiosk WebSockets and relays admin commands to them. Commands
+// and acks go through Redis so any backend instance can reach any kiosk. Each
+// kiosk's commands act on the lottery of the campaign it connected with.
+type KioskHub struct {
+	redis     *repository.RedisRepository
+	campaigns *CampaignService
+
+	mu      sync.Mutex
+	sockets map[string]KioskSocket
+	pending map[string]chan models.KioskAck
+}
+
+// NewKioskHub creates a new kiosk hub
+func NewKioskHub(redis *repository.RedisRepository, campaigns *CampaignService) *KioskHub {
+	return &KioskHub{
+		redis:     redis,
+		campaigns: campaigns,
+		sockets:   make(map[string]KioskSocket),
+		pending:   make(map[string]chan models.KioskAck),
+	}
+}
+
+// HasCampaign reports whether a kiosk may connect with a campaign ID
+func (h *KioskHub) HasCampaign(campaignID string) bool {
+	_, ok := h.campaigns.Get(campaignID)
+	return ok
+}
+
+// lottery returns the lottery of the campaign a kiosk connected with. Kiosks
+// that never named one spin the default campaign.
+func (h *KioskHub) lottery(ctx context.Context, stationID string) (*LotteryService, error) {
+	campaignID, err := h.redis.GetKioskCampaign(ctx, stationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kiosk campaign: %w", err)
+	}
+	if campaignID == "" {
+		campaignID = config.DefaultCampaignID
+	}
+	lottery, ok := h.campaigns.Get(campaignID)
+	if !ok {
+		return nil, ErrKioskCampaign
+	}
+	return lottery, nil
+}
+
+// Prize returns a prize of the campaign a kiosk spins
+func (h *KioskHub) Prize(ctx context.Context, stationID, prizeID string) (config.Prize, bool, error) {
+	lottery, err := h.lottery(ctx, stationID)
+	if err != nil {
+		return config.Prize{}, false, err
+	}
+	prize, ok := lottery.getPrize(prizeID)
+	return prize, ok, nil
+}
+
+// Run relays commands to kiosks connected to this instance and acks to the
+// admins waiting for them, until ctx is cancelled
+func (h *KioskHub) Run(ctx context.Context) {
+	for {
+		if err := h.relay(ctx); err != nil {
+			fmt.Printf("Failed to relay kiosk commands: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(kioskResubscribeDelay):
+		}
+	}
+}
+
+// relay runs until the Redis subscriptions end
+func (h *KioskHub) relay(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	commands, err := h.redis.SubscribeKioskCommands(ctx)
+	if err != nil {
+		return err
+	}
+	acks, err := h.redis.SubscribeKioskAcks(ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case cmd, ok := <-commands:
+			if !ok {
+				return errors.New("command subscription closed")
+			}
+			h.deliver(ctx, cmd)
+		case ack, ok := <-acks:
+			if !ok {
+				return errors.New("ack subscription closed")
+			}
+			h.mu.Lock()
+			waiter := h.pending[ack.CommandID]
+			h.mu.Unlock()
+			if waiter != nil {
+				select {
+				case waiter <- ack:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// deliver writes a command to the kiosk if it is connected to this instance
+func (h *KioskHub) deliver(ctx context.Context, cmd models.KioskCommand) {
+	h.mu.Lock()
+	socket := h.sockets[cmd.StationID]
+	h.mu.Unlock()
+	if socket == nil {
+		return
+	}
+
+	if err := socket.WriteJSON(cmd); err != nil {
+		fmt.Printf("Failed to send command to kiosk %s: %v\n", cmd.StationID, err)
+		h.Acknowledge(ctx, cmd.StationID, models.KioskAck{CommandID: cmd.ID, Error: "failed to send command: " + err.Error()})
+		socket.Close()
+	}
+}
+
+// Connect registers a kiosk's WebSocket and the campaign it spins, replacing
+// any older connection for the same station. The returned func unregisters it.
+func (h *KioskHub) Connect(ctx context.Context, stationID, campaignID string, socket KioskSocket) func() {
+	if err := h.redis.SetKioskCampaign(ctx, stationID, campaignID); err != nil {
+		fmt.Printf("Failed to record kiosk campaign: %v\n", err)
+	}
+
+	h.mu.Lock()
+	previous := h.sockets[stationID]
+	h.sockets[stationID] = socket
+	h.mu.Unlock()
+
+	if previous != nil {
+		previous.Close()
+	}
+	h.Touch(ctx, stationID)
+
+	return func() {
+		h.mu.Lock()
+		current := h.sockets[stationID] == socket
+		if current {
+			delete(h.sockets, stationID)
+		}
+		h.mu.Unlock()
+
+		// A newer connection (maybe on another instance) keeps the kiosk online
+		if current {
+			if err := h.redis.RemoveKiosk(context.Background(), stationID); err != nil {
+				fmt.Printf("Failed to mark kiosk offline: %v\n", err)
+			}
+		}
+	}
+}
+
+// Touch marks a kiosk as online now
+func (h *KioskHub) Touch(ctx context.Context, stationID string) {
+	if err := h.redis.TouchKiosk(ctx, stationID); err != nil {
+		fmt.Printf("Failed to mark kiosk online: %v\n", err)
+	}
+}
+
+// Acknowledge passes a kiosk's reply to the admin waiting for it
+func (h *KioskHub) Acknowledge(ctx context.Context, stationID string, ack models.KioskAck) {
+	ack.StationID = stationID
+	ack.AckedAt = time.Now()
+	if err := h.redis.PublishKioskAck(ctx, ack); err != nil {
+		fmt.Printf("Failed to publish kiosk ack: %v\n", err)
+	}
+}
+
+// Online returns the kiosks with an open connection
+func (h *KioskHub) Online(ctx context.Context) ([]models.KioskPresence, error) {
+	return h.redis.GetOnlineKiosks(ctx, time.Now().Add(-kioskOnlineWindow))
+}
+
+// Send pushes a command to a kiosk and waits for its acknowledgement.
+// A lock command sets the station's prize lock before the kiosk is told.
+func (h *KioskHub) Send(ctx context.Context, stationID string, req models.KioskCommandRequest, actor string) (*models.KioskAck, error) {
+	// Step 1: Validate the command against the kiosk's campaign
+	lottery, err := h.lottery(ctx, stationID)
+	if err != nil {
+		return nil, err
+	}
+	cmd := models.KioskCommand{
+		StationID: stationID,
+		Type:      req.Type,
+		IssuedBy:  actor,
+		IssuedAt:  time.Now(),
+	}
+	switch req.Type {
+	case models.KioskCommandLock:
+		if _, ok := lottery.getPrize(req.PrizeID); !ok {
+			return nil, ErrUnknownPrize
+		}
+		cmd.PrizeID = req.PrizeID
+	case models.KioskCommandMessage:
+		message := strings.TrimSpace(req.Message)
+		if message == "" || len([]rune(message)) > maxKioskMessageLength {
+			return nil, ErrKioskMessageInvalid
+		}
+		cmd.Message = message
+	case models.KioskCommandPause, models.KioskCommandResume, models.KioskCommandSpin:
+	default:
+		return nil, ErrKioskCommandInvalid
+	}
+
+	online, err := h.redis.IsKioskOnline(ctx, stationID, time.Now().Add(-kioskOnlineWindow))
+	if err != nil {
+		return nil, err
+	}
+	if !online {
+		return nil, ErrKioskOffline
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	cmd.ID = id
+
+	// Step 2: Apply server-side effects
+	if cmd.Type == models.KioskCommandLock {
+		if err := lottery.LockPrize(ctx, cmd.PrizeID, stationID, actor); err != nil {
+			return nil, err
+		}
+	}
+
+	// Step 3: Publish and wait for the ack
+	waiter := make(chan models.KioskAck, 1)
+	h.mu.Lock()
+	h.pending[cmd.ID] = waiter
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.pending, cmd.ID)
+		h.mu.Unlock()
+	}()
+
+	if err := h.redis.PublishKioskCommand(ctx, cmd); err != nil {
+		return nil, fmt.Errorf("failed to publish command: %w", err)
+	}
+
+	timer := time.NewTimer(kioskAckTimeout)
+	defer timer.Stop()
+	select {
+	case ack := <-waiter:
+		return &ack, nil
+	case <-timer.C:
+		return nil, ErrKioskAckTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/repository"
+)
+
+// liveFeedQueueSize bounds the events waiting to be published to a feed
+const liveFeedQueueSize = 1024
+
+// liveFeedEvent is an event waiting to be published
+type liveFeedEvent struct {
+	eventType string
+	payload   json.RawMessage
+}
+
+// LiveFeedService publishes events to one SSE feed, such as the admin live
+// feed, shared across backend instances through Redis
+type LiveFeedService struct {
+	redis  *repository.RedisRepository
+	feed   string
+	recent int // events replayed to a client that has no Last-Event-ID
+	queue  chan liveFeedEvent
+}
+
+// NewLiveFeedService creates a new live feed service for a repository feed.
+// Clients connecting for the first time get the last recent events.
+func NewLiveFeedService(redis *repository.RedisRepository, feed string, recent int) *LiveFeedService {
+	s := &LiveFeedService{
+		redis:  redis,
+		feed:   feed,
+		recent: recent,
+		queue:  make(chan liveFeedEvent, liveFeedQueueSize),
+	}
+	go s.run()
+	return s
+}
+
+// run publishes queued events one at a time, so they reach the feed in the
+// order PublishAsync was called
+func (s *LiveFeedService) run() {
+	for event := range s.queue {
+		if _, err := s.redis.PublishLiveEvent(context.Background(), s.feed, event.eventType, event.payload); err != nil {
+			fmt.Printf("Failed to publish live event %s: %v\n", event.eventType, err)
+		}
+	}
+}
+
+// PublishAsync queues an event for the live feed without blocking the caller.
+// If the queue is full the event is dropped.
+func (s *LiveFeedService) PublishAsync(eventType string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		fmt.Printf("Failed to encode live event %s: %v\n", eventType, err)
+		return
+	}
+
+	select {
+	case s.queue <- liveFeedEvent{eventType: eventType, payload: payload}:
+	default:
+		fmt.Printf("Failed to publish live event %s: queue is full\n", eventType)
+	}
+}
+
+// Subscribe returns the events after lastEventID (the recent events if it is
+// empty) and a channel of new events until ctx is cancelled. Events already
+// replayed are not repeated on the channel.
+func (s *LiveFeedService) Subscribe(ctx context.Context, lastEventID string) ([]models.LiveEvent, <-chan models.LiveEvent, error) {
+	// Subscribe before reading the backlog so nothing published in between is lost
+	live, err := s.redis.SubscribeLiveEvents(ctx, s.feed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var backlog []models.LiveEvent
+	switch {
+	case lastEventID != "":
+		if _, _, ok := parseStreamID(lastEventID); !ok {
+			lastEventID = "0"
+		}
+		backlog, err = s.redis.GetLiveEventsSince(ctx, s.feed, lastEventID)
+	case s.recent > 0:
+		backlog, err = s.redis.GetRecentLiveEvents(ctx, s.feed, int64(s.recent))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(backlog) > 0 {
+		lastEventID = backlog[len(backlog)-1].ID
+	}
+
+	events := make(chan models.LiveEvent)
+	go func() {
+		defer close(events)
+		for event := range live {
+			if lastEventID != "" && !streamIDAfter(event.ID, lastEventID) {
+				continue
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return backlog, events, nil
+}
+
+// parseStreamID splits a Redis stream ID "<ms>-<seq>"
+func parseStreamID(id string) (ms, seq uint64, ok bool) {
+	msPart, seqPart, found := strings.Cut(id, "-")
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if !found {
+		return ms, 0, true
+	}
+	seq, err = strconv.ParseUint(seqPart, 10, 64)
+	return ms, seq, err == nil
+}
+
+// streamIDAfter reports whether stream ID a comes after b
+func streamIDAfter(a, b string) bool {
+	aMs, aSeq, aOK := parseStreamID(a)
+	bMs, bSeq, bOK := parseStreamID(b)
+	if !aOK || !bOK {
+		return true
+	}
+	return aMs > bMs || (aMs == bMs && aSeq > bSeq)
+}
@@ -2,7 +2,11 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math/rand"
+	"sort"
 	"time"
 
 	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
@@ -10,37 +14,94 @@ import (
 	"github.com/ChaiyawutTar/pungdip/backend/internal/repository"
 )
 
+var (
+	// ErrSpinInProgress is returned when a spin with the same Idempotency-Key has not finished yet
+	ErrSpinInProgress = errors.New("a spin with this idempotency key is still in progress")
+
+	// ErrPlayerBlocked is returned when the Instagram ID is on the blocked list
+	ErrPlayerBlocked = errors.New("this instagram account is not allowed to spin")
+
+	// ErrSerialNotAssigned is returned when a serial pool win could not be stored with a serial
+	ErrSerialNotAssigned = errors.New("no gift card serial could be assigned")
+)
+
 // LotteryService handles the lottery/spin logic
 type LotteryService struct {
 	config   *config.Config
 	redis    *repository.RedisRepository
 	postgres *repository.PostgresRepository
+	webhooks *WebhookService
+	live     *LiveFeedService
+	display  *DisplayService
 	rng      *rand.Rand
 }
 
 // NewLotteryService creates a new lottery service
-func NewLotteryService(cfg *config.Config, redis *repository.RedisRepository, postgres *repository.PostgresRepository) *LotteryService {
+func NewLotteryService(cfg *config.Config, redis *repository.RedisRepository, postgres *repository.PostgresRepository, webhooks *WebhookService, live *LiveFeedService, display *DisplayService) *LotteryService {
 	return &LotteryService{
 		config:   cfg,
 		redis:    redis,
 		postgres: postgres,
+		webhooks: webhooks,
+		live:     live,
+		display:  display,
 		rng:      rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
-// Spin performs a lottery spin for a user
-func (s *LotteryService) Spin(ctx context.Context, instagramID string) (*models.SpinResult, error) {
+// Spin performs a lottery spin for a user at a kiosk station
+func (s *LotteryService) Spin(ctx context.Context, instagramID, stationID string) (*models.SpinResult, error) {
 	var prizeID string
 	var prizeName string
 	var wasLocked bool
 
-	// Step 1: Check if there's a locked prize
-	lockedPrize, err := s.redis.GetNextPrizeLock(ctx)
+	// Store handles in canonical form so each player is counted once
+	instagramID, err := NormalizeInstagramID(instagramID)
 	if err != nil {
 		return nil, err
 	}
 
-	if lockedPrize != "" {
+	// Refuse spins while the booth is paused
+	pause, err := s.redis.GetBoothPause(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if pause != nil {
+		return nil, ErrBoothPaused
+	}
+
+	// Refuse spins outside the event's opening hours
+	if err := s.checkEventOpen(ctx); err != nil {
+		return nil, err
+	}
+
+	// Refuse blocked players; staff spin for practice
+	if instagramID != "" {
+		list, err := s.postgres.GetPlayerList(ctx, instagramID)
+		if err != nil {
+			return nil, err
+		}
+		switch list {
+		case models.ListBlocked:
+			return nil, ErrPlayerBlocked
+		case models.ListStaff:
+			return s.practiceSpin(instagramID, stationID), nil
+		}
+	}
+
+	// Step 1: Check if there's a locked prize for this station, or a global one.
+	// Taking the lock clears it, whether or not the prize can still be awarded.
+	lockedPrize, err := s.redis.TakeNextPrizeLock(ctx, stationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if prize, ok := s.getPrize(lockedPrize); ok && prize.SerialPool {
+		// Serial pool stock is claimed when the spin is logged in Step 2
+		prizeID = lockedPrize
+		prizeName = prize.Name
+		wasLocked = true
+	} else if lockedPrize != "" {
 		// Attempt to claim the locked prize
 		stock, err := s.redis.DecrStock(ctx, lockedPrize)
 		if err != nil {
@@ -52,13 +113,15 @@ func (s *LotteryService) Spin(ctx context.Context, instagramID string) (*models.
 			prizeID = lockedPrize
 			prizeName = s.getPrizeName(lockedPrize)
 			wasLocked = true
-
-			// Clear the lock
-			s.redis.DeleteNextPrizeLock(ctx)
+			s.recordStockChange(lockedPrize, models.StockLedgerAward, -1, stock, stationID)
+			s.notifyStockLevel(lockedPrize, int(stock), stationID)
 		} else {
 			// Stock depleted, restore and fallback to random
-			s.redis.IncrStock(ctx, lockedPrize)
-			s.redis.DeleteNextPrizeLock(ctx)
+			s.recordStockChange(lockedPrize, models.StockLedgerAward, -1, stock, stationID)
+			if restored, err := s.redis.IncrStock(ctx, lockedPrize); err == nil {
+				s.recordStockChange(lockedPrize, models.StockLedgerRestore, 1, restored, stationID)
+			}
+			s.notifyLockFallback(lockedPrize, stationID)
 			prizeID, prizeName = s.randomPrize()
 		}
 	} else {
@@ -66,32 +129,313 @@ func (s *LotteryService) Spin(ctx context.Context, instagramID string) (*models.
 		prizeID, prizeName = s.randomPrize()
 	}
 
-	// Step 2: Log the transaction asynchronously
+	// Step 2: Log the transaction. Voucher prizes are logged synchronously so
+	// the voucher and claim are stored before the winner sees them; the rest asynchronously
 	log := models.SpinLog{
 		InstagramID: instagramID,
 		PrizeWon:    prizeID,
 		PrizeName:   prizeName,
 		WasLocked:   wasLocked,
+		StationID:   stationID,
 		Timestamp:   time.Now(),
 	}
+
+	result := &models.SpinResult{}
+
+	if prize, ok := s.getPrize(prizeID); ok && (prize.IssuesVoucher || prize.SerialPool) {
+		err := s.logWinningSpin(ctx, log, prize, result)
+		if err == nil && prize.SerialPool {
+			s.notifySerialPoolLevel(ctx, prize, stationID)
+		}
+		if err != nil {
+			// Out of cards or no card could be assigned, fallback to random
+			// like depleted stock
+			if !errors.Is(err, repository.ErrSerialPoolEmpty) {
+				fmt.Printf("Failed to award serial: %v\n", err)
+			}
+			s.notifyLockFallback(prizeID, stationID)
+			prizeID, prizeName = s.randomPrize()
+			wasLocked = false
+			log.PrizeWon, log.PrizeName, log.WasLocked = prizeID, prizeName, wasLocked
+			s.postgres.LogSpinAsync(log)
+		}
+	} else {
+		s.postgres.LogSpinAsync(log)
+	}
+
+	result.Result = prizeID
+	result.PrizeName = prizeName
+	result.IsLocked = wasLocked
+	result.SegmentIndex, result.TargetAngle = s.landingPosition(prizeID)
+
+	// Step 3: Publish events for webhook subscribers, the live feed and the display
+	if wasLocked {
+		s.publish(models.EventLockConsumed, models.LockEvent{
+			PrizeID:   prizeID,
+			PrizeName: prizeName,
+			StationID: stationID,
+		})
+	}
+	s.publish(models.EventSpinCompleted, models.SpinEvent{
+		InstagramID: instagramID,
+		PrizeID:     prizeID,
+		PrizeName:   prizeName,
+		WasLocked:   wasLocked,
+		StationID:   stationID,
+	})
+	if prize, ok := s.getPrize(prizeID); ok {
+		s.display.AnnounceAsync(prize, instagramID, log.Timestamp)
+	}
+
+	return result, nil
+}
+
+// logWinningSpin logs a winning spin together with a new voucher code, pickup
+// claim and, for serial pool prizes, a gift card serial, filling them in on the
+// result. Serial pool wins are never logged without a serial: the error is
+// returned instead, ErrSerialPoolEmpty if no serial is left. For other prizes
+// the prize has already been awarded, so if the voucher cannot be stored the
+// spin is still logged without it.
+func (s *LotteryService) logWinningSpin(ctx context.Context, log models.SpinLog, prize config.Prize, result *models.SpinResult) error {
+	for attempt := 0; attempt < voucherIssueAttempts; attempt++ {
+		var code string
+		var err error
+		if prize.IssuesVoucher {
+			if code, err = generateVoucherCode(); err != nil {
+				break
+			}
+		}
+		claim, err := newClaim(s.config, log)
+		if err != nil {
+			break
+		}
+
+		spinLogID, _, err := s.postgres.LogWinningSpin(ctx, log, code, claim, prize.SerialPool)
+		if err != nil {
+			retry, fatal := classifyWinLogError(err, prize.SerialPool)
+			if retry {
+				continue
+			}
+			if fatal != nil {
+				return fatal
+			}
+			fmt.Printf("Failed to issue voucher: %v\n", err)
+			break
+		}
+
+		claim.SpinLogID = spinLogID
+		result.VoucherCode = code
+		result.ClaimID = claim.ID
+		result.ClaimToken = signClaim(s.config, claim)
+		return nil
+	}
+
+	if prize.SerialPool {
+		return ErrSerialNotAssigned
+	}
 	s.postgres.LogSpinAsync(log)
+	return nil
+}
+
+// classifyWinLogError decides how logWinningSpin handles a failure to log a
+// winning spin. retry is set when the voucher code collided. fatal is the
+// error to return, nil when the spin can still be logged without a voucher.
+func classifyWinLogError(err error, serialPool bool) (retry bool, fatal error) {
+	switch {
+	case errors.Is(err, repository.ErrSerialPoolEmpty):
+		return false, err
+	case errors.Is(err, repository.ErrDuplicate):
+		return true, nil
+	case serialPool:
+		return false, fmt.Errorf("failed to assign serial: %w", err)
+	}
+	return false, nil
+}
+
+// GetWheel returns the wheel layout with the angles of each segment
+func (s *LotteryService) GetWheel() *models.WheelLayout {
+	totalWeight := 0
+	for _, segment := range s.config.Wheel {
+		totalWeight += segment.Weight
+	}
+
+	layout := &models.WheelLayout{Segments: make([]models.WheelSegment, 0, len(s.config.Wheel))}
+	if totalWeight == 0 {
+		return layout
+	}
+
+	cumulative := 0
+	for i, segment := range s.config.Wheel {
+		start := float64(cumulative) / float64(totalWeight) * 360
+		cumulative += segment.Weight
+		end := float64(cumulative) / float64(totalWeight) * 360
+
+		layout.Segments = append(layout.Segments, models.WheelSegment{
+			Index:      i,
+			PrizeID:    segment.PrizeID,
+			Label:      segment.Label,
+			Color:      segment.Color,
+			StartAngle: start,
+			EndAngle:   end,
+		})
+	}
 
-	return &models.SpinResult{
+	return layout
+}
+
+// GetPublicPrizes returns display data for every prize, without stock or trigger details
+func (s *LotteryService) GetPublicPrizes(lang string) []models.PublicPrize {
+	wheel := s.GetWheel()
+
+	prizes := make([]models.PublicPrize, len(s.config.Prizes))
+	for i, prize := range s.config.Prizes {
+		segments := []int{}
+		for _, segment := range wheel.Segments {
+			if segment.PrizeID == prize.ID {
+				segments = append(segments, segment.Index)
+			}
+		}
+
+		prizes[i] = models.PublicPrize{
+			ID:       prize.ID,
+			Name:     prize.LocalizedName(lang),
+			Names:    prize.Names,
+			Icon:     prize.Icon,
+			Color:    prize.Color,
+			Segments: segments,
+		}
+	}
+
+	return prizes
+}
+
+// landingPosition picks one of the prize's segments at random and a point
+// inside it, away from the edges so the pointer never looks ambiguous
+func (s *LotteryService) landingPosition(prizeID string) (int, float64) {
+	var candidates []models.WheelSegment
+	for _, segment := range s.GetWheel().Segments {
+		if segment.PrizeID == prizeID {
+			candidates = append(candidates, segment)
+		}
+	}
+	if len(candidates) == 0 {
+		return -1, 0
+	}
+
+	segment := candidates[s.rng.Intn(len(candidates))]
+	margin := (segment.EndAngle - segment.StartAngle) * 0.2
+	angle := segment.StartAngle + margin + s.rng.Float64()*(segment.EndAngle-segment.StartAngle-2*margin)
+
+	return segment.Index, angle
+}
+
+// SpinIdempotent performs a spin at most once per idempotency key. Retries with
+// the same key within the window return the stored result without touching
+// stock, locks or logs; replayed reports whether that happened.
+func (s *LotteryService) SpinIdempotent(ctx context.Context, key, instagramID, stationID string) (result *models.SpinResult, replayed bool, err error) {
+	// Keys are scoped per station so kiosks cannot collide or read each other's results
+	scopedKey := stationID + ":" + key
+
+	reserved, err := s.redis.ReserveIdempotencyKey(ctx, scopedKey, s.config.IdempotencyWindow)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !reserved {
+		data, pending, err := s.redis.GetIdempotentResult(ctx, scopedKey)
+		if err != nil {
+			return nil, false, err
+		}
+		if pending {
+			return nil, false, ErrSpinInProgress
+		}
+		if data == nil {
+			// Expired between reserve and get; treat as a fresh request
+			return s.SpinIdempotent(ctx, key, instagramID, stationID)
+		}
+
+		var stored models.SpinResult
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return nil, false, fmt.Errorf("invalid stored spin result: %w", err)
+		}
+		return &stored, true, nil
+	}
+
+	result, err = s.Spin(ctx, instagramID, stationID)
+	if err != nil {
+		s.redis.ReleaseIdempotencyKey(ctx, scopedKey)
+		return nil, false, err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode spin result: %w", err)
+	}
+	if err := s.redis.StoreIdempotentResult(ctx, scopedKey, data, s.config.IdempotencyWindow); err != nil {
+		// The spin already happened, so return it rather than failing the request
+		fmt.Printf("Failed to store idempotent result: %v\n", err)
+	}
+
+	return result, false, nil
+}
+
+// practiceSpin spins for a staff member. It takes no lock and uses no stock,
+// and is logged as a practice spin only: no vouchers, events or announcements.
+func (s *LotteryService) practiceSpin(instagramID, stationID string) *models.SpinResult {
+	prizeID, prizeName := s.randomPrize()
+
+	s.postgres.LogPracticeSpinAsync(models.SpinLog{
+		InstagramID: instagramID,
+		PrizeWon:    prizeID,
+		PrizeName:   prizeName,
+		StationID:   stationID,
+		Timestamp:   time.Now(),
+	})
+
+	result := &models.SpinResult{
 		Result:    prizeID,
 		PrizeName: prizeName,
-		IsLocked:  wasLocked,
-	}, nil
+		Practice:  true,
+	}
+	result.SegmentIndex, result.TargetAngle = s.landingPosition(prizeID)
+	return result
 }
 
-// randomPrize returns either NOTHING or GIVE_IG when no prize is locked
-// All other prizes require admin lock/trigger
+// randomPrize picks one of the campaign's untriggered prizes, weighted by
+// probability, when no prize is locked. All other prizes require admin lock/trigger.
+// On the main wheel that is a 50/50 chance of NOTHING or GIVE_IG.
 func (s *LotteryService) randomPrize() (string, string) {
-	// Only 2 possible outcomes when not locked: NOTHING or GIVE_IG
-	// 50/50 chance
-	if s.rng.Intn(2) == 0 {
+	total := 0
+	for _, prize := range s.config.Prizes {
+		if !prize.IsTriggered && prize.Probability > 0 {
+			total += prize.Probability
+		}
+	}
+	if total == 0 {
 		return "NOTHING", "Better Luck Next Time"
 	}
-	return "GIVE_IG", "Give IG"
+
+	pick := s.rng.Intn(total)
+	for _, prize := range s.config.Prizes {
+		if prize.IsTriggered || prize.Probability <= 0 {
+			continue
+		}
+		if pick < prize.Probability {
+			return prize.ID, prize.Name
+		}
+		pick -= prize.Probability
+	}
+	return "NOTHING", "Better Luck Next Time"
+}
+
+// getPrize returns the configuration for a prize ID
+func (s *LotteryService) getPrize(prizeID string) (config.Prize, bool) {
+	for _, prize := range s.config.Prizes {
+		if prize.ID == prizeID {
+			return prize, true
+		}
+	}
+	return config.Prize{}, false
 }
 
 // getPrizeName returns the name for a prize ID
@@ -104,42 +448,152 @@ func (s *LotteryService) getPrizeName(prizeID string) string {
 	return prizeID
 }
 
-// LockPrize sets the next spin to award a specific prize
-func (s *LotteryService) LockPrize(ctx context.Context, prizeID string) error {
-	return s.redis.SetNextPrizeLock(ctx, prizeID)
+// LockPrize sets the next spin at a station to award a specific prize.
+// An empty stationID sets the global lock, used by stations without their own.
+func (s *LotteryService) LockPrize(ctx context.Context, prizeID, stationID, actor string) error {
+	var err error
+	if stationID == "" {
+		err = s.redis.SetNextPrizeLock(ctx, prizeID)
+	} else {
+		err = s.redis.SetStationPrizeLock(ctx, stationID, prizeID)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.publish(models.EventLockSet, models.LockEvent{
+		PrizeID:   prizeID,
+		PrizeName: s.getPrizeName(prizeID),
+		Actor:     actor,
+		StationID: stationID,
+	})
+	return nil
 }
 
-// GetLockStatus returns the current lock status
+// GetLockStatus returns the global lock and the lock for each station
 func (s *LotteryService) GetLockStatus(ctx context.Context) (*models.LockStatus, error) {
 	locked, err := s.redis.GetNextPrizeLock(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return &models.LockStatus{
+	stationLocks, err := s.redis.GetStationPrizeLocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.LockStatus{
 		IsLocked:      locked != "",
 		LockedPrizeID: locked,
-	}, nil
+		StationLocks:  make([]models.StationLock, 0, len(stationLocks)),
+	}
+	for stationID, prizeID := range stationLocks {
+		status.StationLocks = append(status.StationLocks, models.StationLock{StationID: stationID, PrizeID: prizeID})
+	}
+	sort.Slice(status.StationLocks, func(i, j int) bool {
+		return status.StationLocks[i].StationID < status.StationLocks[j].StationID
+	})
+	return status, nil
+}
+
+// UnlockPrize removes the prize lock for a station, or the global lock if stationID is empty
+func (s *LotteryService) UnlockPrize(ctx context.Context, stationID, actor string) error {
+	var err error
+	if stationID == "" {
+		err = s.redis.DeleteNextPrizeLock(ctx)
+	} else {
+		err = s.redis.DeleteStationPrizeLock(ctx, stationID)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.live.PublishAsync(models.EventLockCleared, models.LockEvent{Actor: actor, StationID: stationID})
+	return nil
 }
 
-// UnlockPrize removes any prize lock
-func (s *LotteryService) UnlockPrize(ctx context.Context) error {
-	return s.redis.DeleteNextPrizeLock(ctx)
+// publish sends an event to webhook subscribers and the admin live feed
+func (s *LotteryService) publish(eventType string, data interface{}) {
+	s.webhooks.PublishAsync(eventType, data)
+	s.live.PublishAsync(eventType, data)
 }
 
-// ResetStocks resets all stocks to default values
-func (s *LotteryService) ResetStocks(ctx context.Context) error {
-	return s.redis.ResetStocks(ctx)
+// ResetStocks resets all stocks to default values and records the reset in the stock ledger
+func (s *LotteryService) ResetStocks(ctx context.Context, actor string) error {
+	before := make(map[string]int)
+	for _, prize := range s.countedPrizes() {
+		stock, err := s.redis.GetStock(ctx, prize.ID)
+		if err != nil || stock < 0 {
+			stock = 0
+		}
+		before[prize.ID] = stock
+	}
+
+	if err := s.redis.ResetStocks(ctx); err != nil {
+		return err
+	}
+
+	stocks := make(map[string]int)
+	for _, prize := range s.countedPrizes() {
+		s.postgres.AppendStockLedger(ctx, models.StockLedgerEntry{
+			PrizeID:    prize.ID,
+			Kind:       models.StockLedgerReset,
+			Change:     prize.Stock - before[prize.ID],
+			StockAfter: prize.Stock,
+			Actor:      actor,
+			Timestamp:  time.Now(),
+		})
+		stocks[prize.ID] = prize.Stock
+	}
+
+	s.publish(models.EventStockReset, models.StockResetEvent{Actor: actor, Stocks: stocks})
+	return nil
 }
 
 // GetRecentLogs returns recent spin logs
+// Voucher codes are masked: staff look a code up with the winner's copy.
 func (s *LotteryService) GetRecentLogs(ctx context.Context, limit int) ([]models.SpinLog, error) {
-	return s.postgres.GetRecentLogs(ctx, limit)
+	logs, err := s.postgres.GetRecentLogs(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range logs {
+		logs[i].VoucherCode = maskVoucherCode(logs[i].VoucherCode)
+	}
+	return logs, nil
 }
 
-// GetStocks returns stock status for all limited prizes
+// GetPracticeLogs returns recent staff practice spins
+func (s *LotteryService) GetPracticeLogs(ctx context.Context, limit int) ([]models.SpinLog, error) {
+	return s.postgres.GetRecentPracticeSpins(ctx, limit)
+}
+
+// GetStocks returns stock status for all limited prizes.
+// Serial pool prizes report the serials left and imported.
 func (s *LotteryService) GetStocks(ctx context.Context) ([]map[string]interface{}, error) {
-	return s.redis.GetAllStocks(ctx)
+	stocks, err := s.redis.GetAllStocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, prize := range s.config.Prizes {
+		if !prize.SerialPool {
+			continue
+		}
+		available, total, err := s.postgres.GetSerialPoolCounts(ctx, prize.ID)
+		if err != nil {
+			return nil, err
+		}
+		stocks = append(stocks, map[string]interface{}{
+			"prize_id":    prize.ID,
+			"name":        prize.Name,
+			"stock":       available,
+			"max":         total,
+			"serial_pool": true,
+		})
+	}
+
+	return stocks, nil
 }
 
 // GetStats returns overall statistics
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/repository"
+)
+
+func TestClassifyWinLogError(t *testing.T) {
+	dbDown := errors.New("connection refused")
+
+	tests := []struct {
+		name       string
+		err        error
+		serialPool bool
+		retry      bool
+		fatal      error // nil means the spin is logged without a voucher
+	}{
+		{"pool empty", repository.ErrSerialPoolEmpty, true, false, repository.ErrSerialPoolEmpty},
+		{"pool empty wrapped", fmt.Errorf("failed to log spin: %w", repository.ErrSerialPoolEmpty), true, false, repository.ErrSerialPoolEmpty},
+		{"voucher collision on serial prize", repository.ErrDuplicate, true, true, nil},
+		{"voucher collision", repository.ErrDuplicate, false, true, nil},
+		{"serial not assigned", dbDown, true, false, dbDown},
+		{"voucher not stored", dbDown, false, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retry, fatal := classifyWinLogError(tt.err, tt.serialPool)
+			if retry != tt.retry {
+				t.Errorf("retry = %v, want %v", retry, tt.retry)
+			}
+			if !errors.Is(fatal, tt.fatal) {
+				t.Errorf("fatal = %v, want %v", fatal, tt.fatal)
+			}
+		})
+	}
+}
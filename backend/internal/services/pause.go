@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+)
+
+const maxPauseMessageLength = 200
+
+var (
+	ErrBoothPaused       = errors.New("booth is paused")
+	ErrPauseMessage      = errors.New("pause message must be at most 200 characters")
+	ErrPauseResumeFormat = errors.New("send either resume_at or resume_in, as a future time or a positive duration such as 15m")
+)
+
+// PauseBooth stops spins until ResumeBooth is called or, if resumeAt or
+// resumeIn is given, until then. Admin routes keep working.
+func (s *LotteryService) PauseBooth(ctx context.Context, message string, resumeAt *time.Time, resumeIn, actor string) (*models.BoothPause, error) {
+	message = strings.TrimSpace(message)
+	if len([]rune(message)) > maxPauseMessageLength {
+		return nil, ErrPauseMessage
+	}
+
+	now := time.Now()
+	resumeAt, err := pauseResumeTime(resumeAt, resumeIn, now)
+	if err != nil {
+		return nil, err
+	}
+
+	pause := models.BoothPause{
+		Message:  message,
+		ResumeAt: resumeAt,
+		PausedBy: actor,
+		PausedAt: now,
+	}
+	if err := s.redis.SetBoothPause(ctx, pause); err != nil {
+		return nil, err
+	}
+
+	s.live.PublishAsync(models.EventBoothPaused, pause)
+	return &pause, nil
+}
+
+// pauseResumeTime works out when a pause paused at now auto-resumes, from
+// either an absolute time or a duration. Returns nil for an open-ended pause.
+func pauseResumeTime(resumeAt *time.Time, resumeIn string, now time.Time) (*time.Time, error) {
+	if resumeIn != "" {
+		if resumeAt != nil {
+			return nil, ErrPauseResumeFormat
+		}
+		d, err := time.ParseDuration(resumeIn)
+		if err != nil || d <= 0 {
+			return nil, ErrPauseResumeFormat
+		}
+		at := now.Add(d)
+		resumeAt = &at
+	}
+	if resumeAt != nil && !resumeAt.After(now) {
+		return nil, ErrPauseResumeFormat
+	}
+	return resumeAt, nil
+}
+
+// ResumeBooth lets spins through again
+func (s *LotteryService) ResumeBooth(ctx context.Context, actor string) error {
+	if err := s.redis.DeleteBoothPause(ctx); err != nil {
+		return err
+	}
+
+	s.live.PublishAsync(models.EventBoothResumed, models.BoothResumedEvent{Actor: actor})
+	return nil
+}
+
+// GetBoothPause returns the current pause, or nil if the booth is open
+func (s *LotteryService) GetBoothPause(ctx context.Context) (*models.BoothPause, error) {
+	return s.redis.GetBoothPause(ctx)
+}
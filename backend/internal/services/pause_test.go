@@ -0,0 +1,44 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPauseResumeTime(t *testing.T) {
+	now := time.Date(2026, 3, 14, 15, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	tests := []struct {
+		name     string
+		resumeAt *time.Time
+		resumeIn string
+		want     *time.Time
+		err      error
+	}{
+		{"open-ended", nil, "", nil, nil},
+		{"resume in", nil, "15m", at(15 * time.Minute), nil},
+		{"resume at", at(time.Hour), "", at(time.Hour), nil},
+		{"both given", at(time.Hour), "15m", nil, ErrPauseResumeFormat},
+		{"zero duration", nil, "0s", nil, ErrPauseResumeFormat},
+		{"negative duration", nil, "-5m", nil, ErrPauseResumeFormat},
+		{"not a duration", nil, "soon", nil, ErrPauseResumeFormat},
+		{"resume at now", at(0), "", nil, ErrPauseResumeFormat},
+		{"resume at in the past", at(-time.Minute), "", nil, ErrPauseResumeFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pauseResumeTime(tt.resumeAt, tt.resumeIn, now)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("pauseResumeTime() error = %v, want %v", err, tt.err)
+			}
+			if (got == nil) != (tt.want == nil) || got != nil && !got.Equal(*tt.want) {
+				t.Errorf("pauseResumeTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
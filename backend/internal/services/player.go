@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/repository"
+)
+
+const (
+	// playerHistoryLimit is the number of recent spins returned with a profile
+	playerHistoryLimit = 100
+
+	maxPlayerNotesLength = 2000
+
+	maxListReasonLength = 500
+)
+
+var (
+	ErrPlayerNotFound     = errors.New("player not found")
+	ErrPlayerNotesTooLong = errors.New("notes must be at most 2000 characters")
+	ErrListInvalid        = errors.New("list must be blocked or staff")
+	ErrListReasonTooLong  = errors.New("reason must be at most 500 characters")
+	ErrListEntryRequired  = errors.New("instagram_id is required")
+	ErrListNotListed      = errors.New("instagram id is not on this list")
+	ErrListCSVInvalid     = errors.New("list upload is not valid CSV")
+	ErrListCSVEmpty       = errors.New("no instagram ids found in upload")
+)
+
+// PlayerService looks up who has played before and what they won
+type PlayerService struct {
+	postgres *repository.PostgresRepository
+}
+
+// NewPlayerService creates a new player service
+func NewPlayerService(postgres *repository.PostgresRepository) *PlayerService {
+	return &PlayerService{postgres: postgres}
+}
+
+// Get returns a player's profile and recent spins. The Instagram ID is
+// normalized, so "@Foo" finds "foo".
+func (s *PlayerService) Get(ctx context.Context, instagramID string) (*models.PlayerProfile, error) {
+	id, err := NormalizeInstagramID(instagramID)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return nil, ErrPlayerNotFound
+	}
+
+	player, err := s.postgres.GetPlayer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if player == nil {
+		return nil, ErrPlayerNotFound
+	}
+
+	history, err := s.postgres.GetPlayerSpins(ctx, id, playerHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PlayerProfile{Player: *player, History: history}, nil
+}
+
+// SetNotes replaces the staff notes on a player's profile
+func (s *PlayerService) SetNotes(ctx context.Context, instagramID, notes string) error {
+	id, err := NormalizeInstagramID(instagramID)
+	if err != nil {
+		return err
+	}
+	if len([]rune(notes)) > maxPlayerNotesLength {
+		return ErrPlayerNotesTooLong
+	}
+
+	found, err := s.postgres.SetPlayerNotes(ctx, id, notes)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrPlayerNotFound
+	}
+	return nil
+}
+
+// validList returns ErrListInvalid unless list is blocked or staff
+func validList(list string) error {
+	if list != models.ListBlocked && list != models.ListStaff {
+		return ErrListInvalid
+	}
+	return nil
+}
+
+// List returns the Instagram IDs on the blocked or staff list
+func (s *PlayerService) List(ctx context.Context, list string) ([]models.ListedPlayer, error) {
+	if err := validList(list); err != nil {
+		return nil, err
+	}
+	return s.postgres.ListPlayers(ctx, list)
+}
+
+// AddToList puts an Instagram ID on the blocked or staff list, taking it off the other one
+func (s *PlayerService) AddToList(ctx context.Context, list, instagramID, reason, actor string) (*models.ListedPlayer, error) {
+	if err := validList(list); err != nil {
+		return nil, err
+	}
+	id, err := NormalizeInstagramID(instagramID)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return nil, ErrListEntryRequired
+	}
+	reason = strings.TrimSpace(reason)
+	if len([]rune(reason)) > maxListReasonLength {
+		return nil, ErrListReasonTooLong
+	}
+
+	player := models.ListedPlayer{
+		InstagramID: id,
+		List:        list,
+		Reason:      reason,
+		AddedBy:     actor,
+		AddedAt:     time.Now(),
+	}
+	if err := s.postgres.AddToPlayerList(ctx, []models.ListedPlayer{player}); err != nil {
+		return nil, err
+	}
+	return &player, nil
+}
+
+// RemoveFromList takes an Instagram ID off the blocked or staff list
+func (s *PlayerService) RemoveFromList(ctx context.Context, list, instagramID string) error {
+	if err := validList(list); err != nil {
+		return err
+	}
+	id, err := NormalizeInstagramID(instagramID)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.postgres.RemoveFromPlayerList(ctx, list, id)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrListNotListed
+	}
+	return nil
+}
+
+// ImportList adds Instagram IDs from a CSV upload to the blocked or staff
+// list. The ID is read from the first column and an optional reason from the
+// second; an "instagram_id" header row and blank lines are skipped. Invalid
+// IDs are skipped and reported rather than failing the import.
+func (s *PlayerService) ImportList(ctx context.Context, list string, upload io.Reader, actor string) (*models.ListImportResult, error) {
+	if err := validList(list); err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(upload)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	now := time.Now()
+	result := &models.ListImportResult{List: list}
+	seen := make(map[string]bool)
+	var players []models.ListedPlayer
+	for line := 0; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrListCSVInvalid
+		}
+
+		raw := strings.TrimSpace(record[0])
+		if raw == "" || (line == 0 && strings.EqualFold(raw, "instagram_id")) {
+			continue
+		}
+		id, err := NormalizeInstagramID(raw)
+		if err != nil {
+			result.Invalid = append(result.Invalid, raw)
+			continue
+		}
+		if seen[id] {
+			continue
+		}
+
+		var reason string
+		if len(record) > 1 {
+			reason = strings.TrimSpace(record[1])
+		}
+		if len([]rune(reason)) > maxListReasonLength {
+			return nil, ErrListReasonTooLong
+		}
+
+		seen[id] = true
+		players = append(players, models.ListedPlayer{
+			InstagramID: id,
+			List:        list,
+			Reason:      reason,
+			AddedBy:     actor,
+			AddedAt:     now,
+		})
+	}
+
+	if len(players) == 0 && len(result.Invalid) == 0 {
+		return nil, ErrListCSVEmpty
+	}
+	if err := s.postgres.AddToPlayerList(ctx, players); err != nil {
+		return nil, err
+	}
+	result.Imported = len(players)
+	return result, nil
+}
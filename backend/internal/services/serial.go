@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+)
+
+// maxSerialLength matches the gift_card_serials.serial column
+const maxSerialLength = 100
+
+var (
+	ErrNotSerialPool    = errors.New("prize does not use a gift card serial pool")
+	ErrNoSerials        = errors.New("no serials found in upload")
+	ErrSerialTooLong    = fmt.Errorf("serials must be at most %d characters", maxSerialLength)
+	ErrSerialCSVInvalid = errors.New("serial upload is not valid CSV")
+)
+
+// ImportSerials adds gift card serials from a CSV upload to a prize's pool.
+// The serial is read from the first column; a "serial" header row, blank lines
+// and serials already imported are skipped. New serials are recorded in the
+// stock ledger.
+func (s *LotteryService) ImportSerials(ctx context.Context, prizeID string, upload io.Reader, actor string) (*models.SerialImportResult, error) {
+	prize, ok := s.getPrize(prizeID)
+	if !ok || !prize.SerialPool {
+		return nil, ErrNotSerialPool
+	}
+
+	serials, err := parseSerialCSV(upload)
+	if err != nil {
+		return nil, err
+	}
+
+	imported, err := s.postgres.ImportSerials(ctx, prizeID, serials)
+	if err != nil {
+		return nil, err
+	}
+
+	available, _, err := s.postgres.GetSerialPoolCounts(ctx, prizeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if imported > 0 {
+		s.postgres.AppendStockLedger(ctx, models.StockLedgerEntry{
+			PrizeID:    prizeID,
+			Kind:       models.StockLedgerImport,
+			Change:     imported,
+			StockAfter: available,
+			Reason:     "serial import",
+			Actor:      actor,
+			Timestamp:  time.Now(),
+		})
+		s.live.PublishAsync(models.EventStockChanged, models.StockChange{PrizeID: prizeID, Kind: models.StockLedgerImport, Change: imported, Stock: available})
+	}
+
+	return &models.SerialImportResult{
+		PrizeID:    prizeID,
+		Imported:   imported,
+		Duplicates: len(serials) - imported,
+		Available:  available,
+	}, nil
+}
+
+// parseSerialCSV returns the unique serials in the first column of a CSV
+func parseSerialCSV(upload io.Reader) ([]string, error) {
+	reader := csv.NewReader(upload)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	seen := make(map[string]bool)
+	var serials []string
+	for line := 0; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrSerialCSVInvalid
+		}
+
+		serial := strings.TrimSpace(record[0])
+		if serial == "" || (line == 0 && strings.EqualFold(serial, "serial")) || seen[serial] {
+			continue
+		}
+		if len(serial) > maxSerialLength {
+			return nil, ErrSerialTooLong
+		}
+
+		seen[serial] = true
+		serials = append(serials, serial)
+	}
+
+	if len(serials) == 0 {
+		return nil, ErrNoSerials
+	}
+	return serials, nil
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/repository"
+)
+
+const (
+	// stationKeyPrefix marks kiosk API keys so they are recognisable in logs and configs
+	stationKeyPrefix = "kiosk_"
+
+	// stationKeyShownChars is how much of the key is kept in clear to identify it
+	stationKeyShownChars = len(stationKeyPrefix) + 6
+)
+
+var (
+	ErrInvalidStationID = errors.New("station ID must be 1-50 characters of a-z, 0-9, - or _")
+	ErrStationExists    = errors.New("station already exists")
+	ErrStationNotFound  = errors.New("station not found or already revoked")
+
+	ErrSignatureMissing = errors.New("missing request signature")
+	ErrSignatureInvalid = errors.New("invalid request signature")
+	ErrSignatureExpired = errors.New("request timestamp outside allowed window")
+	ErrNonceReused      = errors.New("request nonce already used")
+
+	stationIDPattern = regexp.MustCompile(`^[a-z0-9_-]{1,50}$`)
+)
+
+// StationService manages kiosk registration, API keys and request signatures
+type StationService struct {
+	config   *config.Config
+	redis    *repository.RedisRepository
+	postgres *repository.PostgresRepository
+}
+
+// NewStationService creates a new station service
+func NewStationService(cfg *config.Config, redis *repository.RedisRepository, postgres *repository.PostgresRepository) *StationService {
+	return &StationService{
+		config:   cfg,
+		redis:    redis,
+		postgres: postgres,
+	}
+}
+
+// SignedRequest holds the parts of a kiosk request covered by its signature
+type SignedRequest struct {
+	Method    string
+	Path      string
+	Body      []byte
+	Timestamp string // Unix seconds
+	Nonce     string
+	Signature string // Hex HMAC-SHA256
+}
+
+// SigningPayload builds the string a kiosk signs:
+// timestamp, nonce, method, path and the hex SHA-256 of the body, joined by newlines
+func SigningPayload(req SignedRequest) string {
+	bodyHash := sha256.Sum256(req.Body)
+	return req.Timestamp + "\n" + req.Nonce + "\n" + req.Method + "\n" + req.Path + "\n" + hex.EncodeToString(bodyHash[:])
+}
+
+// Register creates a station and returns its API key, which is only stored hashed
+func (s *StationService) Register(ctx context.Context, id, name string) (*models.StationRegistration, error) {
+	if !stationIDPattern.MatchString(id) {
+		return nil, ErrInvalidStationID
+	}
+	if name == "" {
+		name = id
+	}
+
+	key, err := randomHex(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key = stationKeyPrefix + key
+
+	signingSecret, err := randomHex(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing secret: %w", err)
+	}
+
+	station := models.Station{
+		ID:            id,
+		Name:          name,
+		KeyPrefix:     key[:stationKeyShownChars],
+		SigningSecret: signingSecret,
+		CreatedAt:     time.Now(),
+	}
+
+	existing, err := s.postgres.GetStation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrStationExists
+	}
+
+	if err := s.postgres.CreateStation(ctx, station, hashToken(key)); err != nil {
+		return nil, err
+	}
+
+	return &models.StationRegistration{Station: station, APIKey: key, SigningSecret: signingSecret}, nil
+}
+
+// RotateSigningSecret issues a new request signing secret for a station,
+// invalidating the old one. The API key is unchanged.
+func (s *StationService) RotateSigningSecret(ctx context.Context, id string) (*models.StationRegistration, error) {
+	signingSecret, err := randomHex(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing secret: %w", err)
+	}
+
+	updated, err := s.postgres.SetStationSigningSecret(ctx, id, signingSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrStationNotFound
+	}
+
+	station, err := s.postgres.GetStation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &models.StationRegistration{Station: *station, SigningSecret: signingSecret}, nil
+}
+
+// VerifySignature checks a kiosk request's HMAC signature, rejecting stale
+// timestamps and nonces seen before. The signing secret is never sent over
+// the wire, so a captured request cannot be re-signed.
+func (s *StationService) VerifySignature(ctx context.Context, station *models.Station, req SignedRequest) error {
+	if err := checkSignature(station.SigningSecret, req, time.Now(), s.config.SignatureMaxSkew); err != nil {
+		return err
+	}
+
+	// Step 3: Claim the nonce for as long as the timestamp could still be accepted
+	fresh, err := s.redis.ClaimNonce(ctx, station.ID, req.Nonce, 2*s.config.SignatureMaxSkew)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrNonceReused
+	}
+
+	return nil
+}
+
+// checkSignature verifies a request's timestamp and HMAC against the
+// station's signing secret as of now. It does not claim the nonce.
+func checkSignature(secret string, req SignedRequest, now time.Time, maxSkew time.Duration) error {
+	if req.Timestamp == "" || req.Nonce == "" || req.Signature == "" {
+		return ErrSignatureMissing
+	}
+	if secret == "" || len(req.Nonce) > 64 {
+		return ErrSignatureInvalid
+	}
+
+	// Step 1: Reject timestamps outside the allowed skew
+	unix, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+	skew := now.Sub(time.Unix(unix, 0))
+	if skew < -maxSkew || skew > maxSkew {
+		return ErrSignatureExpired
+	}
+
+	// Step 2: Compare the signature in constant time
+	expected, err := hex.DecodeString(req.Signature)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(SigningPayload(req)))
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+// Authenticate resolves the station for an API key.
+// Returns nil if the key is unknown or revoked.
+func (s *StationService) Authenticate(ctx context.Context, key string) (*models.Station, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	station, err := s.postgres.GetStationByKeyHash(ctx, hashToken(key))
+	if err != nil || station == nil {
+		return nil, err
+	}
+
+	s.postgres.TouchStationAsync(station.ID)
+	return station, nil
+}
+
+// List returns all registered stations
+func (s *StationService) List(ctx context.Context) ([]models.Station, error) {
+	return s.postgres.ListStations(ctx)
+}
+
+// Revoke disables a station's API key so it can no longer spin
+func (s *StationService) Revoke(ctx context.Context, id string) error {
+	revoked, err := s.postgres.RevokeStation(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrStationNotFound
+	}
+	return nil
+}
+
+// randomHex returns n random bytes, hex encoded
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/repository"
+)
+
+const testSigningSecret = "station-secret"
+
+// signRequest signs req the way a kiosk does
+func signRequest(secret string, req SignedRequest) SignedRequest {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(SigningPayload(req)))
+	req.Signature = hex.EncodeToString(mac.Sum(nil))
+	return req
+}
+
+func TestSigningPayload(t *testing.T) {
+	req := SignedRequest{Method: "POST", Path: "/api/spin", Body: []byte(`{"userId":"u1"}`), Timestamp: "1700000000", Nonce: "abc"}
+	bodyHash := sha256.Sum256(req.Body)
+	want := "1700000000\nabc\nPOST\n/api/spin\n" + hex.EncodeToString(bodyHash[:])
+	if got := SigningPayload(req); got != want {
+		t.Errorf("SigningPayload() = %q, want %q", got, want)
+	}
+}
+
+func TestCheckSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	skew := time.Minute
+	valid := signRequest(testSigningSecret, SignedRequest{
+		Method:    "POST",
+		Path:      "/api/spin",
+		Body:      []byte(`{"userId":"u1"}`),
+		Timestamp: strconv.FormatInt(now.Unix(), 10),
+		Nonce:     "nonce-1",
+	})
+	at := func(offset time.Duration) SignedRequest {
+		req := valid
+		req.Timestamp = strconv.FormatInt(now.Add(offset).Unix(), 10)
+		return signRequest(testSigningSecret, req)
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		req    func() SignedRequest
+		want   error
+	}{
+		{"valid", testSigningSecret, func() SignedRequest { return valid }, nil},
+		{"edge of window", testSigningSecret, func() SignedRequest { return at(-skew) }, nil},
+		{"stale timestamp", testSigningSecret, func() SignedRequest { return at(-skew - time.Second) }, ErrSignatureExpired},
+		{"future timestamp", testSigningSecret, func() SignedRequest { return at(skew + time.Second) }, ErrSignatureExpired},
+		{"tampered body", testSigningSecret, func() SignedRequest { r := valid; r.Body = []byte(`{"userId":"u2"}`); return r }, ErrSignatureInvalid},
+		{"tampered path", testSigningSecret, func() SignedRequest { r := valid; r.Path = "/api/claim"; return r }, ErrSignatureInvalid},
+		{"tampered method", testSigningSecret, func() SignedRequest { r := valid; r.Method = "PUT"; return r }, ErrSignatureInvalid},
+		{"swapped nonce", testSigningSecret, func() SignedRequest { r := valid; r.Nonce = "nonce-2"; return r }, ErrSignatureInvalid},
+		{"wrong secret", "other-secret", func() SignedRequest { return valid }, ErrSignatureInvalid},
+		{"no secret", "", func() SignedRequest { return valid }, ErrSignatureInvalid},
+		{"signature not hex", testSigningSecret, func() SignedRequest { r := valid; r.Signature = "zz"; return r }, ErrSignatureInvalid},
+		{"timestamp not a number", testSigningSecret, func() SignedRequest { r := valid; r.Timestamp = "soon"; return r }, ErrSignatureInvalid},
+		{"nonce too long", testSigningSecret, func() SignedRequest {
+			r := valid
+			r.Nonce = strings.Repeat("n", 65)
+			return signRequest(testSigningSecret, r)
+		}, ErrSignatureInvalid},
+		{"missing timestamp", testSigningSecret, func() SignedRequest { r := valid; r.Timestamp = ""; return r }, ErrSignatureMissing},
+		{"missing nonce", testSigningSecret, func() SignedRequest { r := valid; r.Nonce = ""; return r }, ErrSignatureMissing},
+		{"missing signature", testSigningSecret, func() SignedRequest { r := valid; r.Signature = ""; return r }, ErrSignatureMissing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkSignature(tt.secret, tt.req(), now, skew); !errors.Is(err, tt.want) {
+				t.Errorf("checkSignature() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifySignatureNonceReplay(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cfg := &config.Config{SignatureMaxSkew: time.Minute}
+	redis, err := repository.NewRedisRepository(mr.Addr(), cfg, repository.NewEventScope())
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := NewStationService(cfg, redis, nil)
+	ctx := context.Background()
+	kiosk := &models.Station{ID: "kiosk-1", SigningSecret: testSigningSecret}
+	other := &models.Station{ID: "kiosk-2", SigningSecret: testSigningSecret}
+	req := signRequest(testSigningSecret, SignedRequest{
+		Method:    "POST",
+		Path:      "/api/spin",
+		Timestamp: strconv.FormatInt(time.Now().Unix(), 10),
+		Nonce:     "nonce-1",
+	})
+
+	tests := []struct {
+		name    string
+		station *models.Station
+		advance time.Duration
+		want    error
+	}{
+		{"first use", kiosk, 0, nil},
+		{"replayed", kiosk, 0, ErrNonceReused},
+		{"same nonce on another station", other, 0, nil},
+		{"still held past the skew window", kiosk, time.Minute + time.Second, ErrNonceReused},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr.FastForward(tt.advance)
+			if err := svc.VerifySignature(ctx, tt.station, req); !errors.Is(err, tt.want) {
+				t.Errorf("VerifySignature() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+)
+
+// reconcileReason is recorded on ledger entries written by a reconcile rebuild
+const reconcileReason = "reconcile: rebuilt from stock ledger and spin logs"
+
+var (
+	ErrUnknownPrize          = errors.New("unknown prize ID")
+	ErrStockNotCounted       = errors.New("prize has unlimited stock and cannot be adjusted")
+	ErrStockSerialPool       = errors.New("serial pool stock is the number of unassigned serials; import serials with POST /api/admin/prizes/:id/serials instead")
+	ErrStockAdjustmentFormat = errors.New("exactly one of stock or delta is required")
+	ErrStockReasonRequired   = errors.New("a reason is required for stock adjustments")
+	ErrStockNegative         = errors.New("stock cannot go below zero")
+)
+
+// AdjustStock sets a prize's stock to an absolute value or changes it by a
+// delta, and records the change with its reason in the stock ledger
+func (s *LotteryService) AdjustStock(ctx context.Context, prizeID string, stock, delta *int, reason, actor string) (*models.StockAdjustment, error) {
+	// Step 1: Validate against the prize catalog
+	prize, ok := s.getPrize(prizeID)
+	if !ok {
+		return nil, ErrUnknownPrize
+	}
+	if prize.SerialPool {
+		return nil, ErrStockSerialPool
+	}
+	if !isCounted(prize) {
+		return nil, ErrStockNotCounted
+	}
+	if (stock == nil) == (delta == nil) {
+		return nil, ErrStockAdjustmentFormat
+	}
+	if reason == "" {
+		return nil, ErrStockReasonRequired
+	}
+
+	// Step 2: Apply atomically in Redis
+	value, isDelta := 0, delta != nil
+	if isDelta {
+		value = *delta
+	} else {
+		value = *stock
+	}
+	if !isDelta && value < 0 {
+		return nil, ErrStockNegative
+	}
+
+	before, after, err := s.redis.AdjustStock(ctx, prizeID, value, isDelta)
+	if err != nil {
+		return nil, err
+	}
+	if after < 0 {
+		return nil, ErrStockNegative
+	}
+
+	// Step 3: Record in the ledger. Redis already holds the new stock, so a
+	// ledger failure is logged rather than failing the request
+	s.postgres.AppendStockLedger(ctx, models.StockLedgerEntry{
+		PrizeID:    prizeID,
+		Kind:       models.StockLedgerAdjust,
+		Change:     after - before,
+		StockAfter: after,
+		Reason:     reason,
+		Actor:      actor,
+		Timestamp:  time.Now(),
+	})
+	s.live.PublishAsync(models.EventStockChanged, models.StockChange{PrizeID: prizeID, Kind: models.StockLedgerAdjust, Change: after - before, Stock: after})
+
+	return &models.StockAdjustment{PrizeID: prizeID, Before: before, After: after}, nil
+}
+
+// isCounted reports whether a prize's stock is a Redis counter
+func isCounted(prize config.Prize) bool {
+	return prize.Stock > 0 && !prize.SerialPool
+}
+
+// countedPrizes returns the prizes whose stock is a Redis counter
+func (s *LotteryService) countedPrizes() []config.Prize {
+	var prizes []config.Prize
+	for _, prize := range s.config.Prizes {
+		if isCounted(prize) {
+			prizes = append(prizes, prize)
+		}
+	}
+	return prizes
+}
+
+// serialPoolPrizes returns the prizes awarded from a gift card serial pool
+func (s *LotteryService) serialPoolPrizes() []config.Prize {
+	var prizes []config.Prize
+	for _, prize := range s.config.Prizes {
+		if prize.SerialPool {
+			prizes = append(prizes, prize)
+		}
+	}
+	return prizes
+}
+
+// recordStockChange appends a spin's stock change to the ledger asynchronously.
+// Unlimited prizes are skipped.
+func (s *LotteryService) recordStockChange(prizeID, kind string, change int, stockAfter int64, stationID string) {
+	if prize, ok := s.getPrize(prizeID); !ok || (!isCounted(prize) && !prize.SerialPool) {
+		return
+	}
+
+	s.postgres.AppendStockLedgerAsync(models.StockLedgerEntry{
+		PrizeID:    prizeID,
+		Kind:       kind,
+		Change:     change,
+		StockAfter: int(stockAfter),
+		Actor:      stationID,
+		Timestamp:  time.Now(),
+	})
+	s.live.PublishAsync(models.EventStockChanged, models.StockChange{PrizeID: prizeID, Kind: kind, Change: change, Stock: int(stockAfter)})
+}
+
+// SeedStocks creates missing stock counters at startup. Existing counters are
+// kept. A prize with ledger history is rebuilt from it, so a Redis flush does
+// not hand out the defaults again; otherwise it gets its configured stock.
+func (s *LotteryService) SeedStocks(ctx context.Context) error {
+	for _, prize := range s.countedPrizes() {
+		exists, err := s.redis.StockExists(ctx, prize.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		anchor, err := s.postgres.GetStockLedgerAnchor(ctx, prize.ID)
+		if err != nil {
+			return err
+		}
+
+		stock, reason := prize.Stock, "initial stock"
+		if anchor != nil {
+			rec, err := s.reconcilePrize(ctx, prize)
+			if err != nil {
+				return err
+			}
+			stock, reason = max(rec.Expected, 0), reconcileReason
+		}
+
+		seeded, err := s.redis.SeedStock(ctx, prize.ID, stock)
+		if err != nil {
+			return err
+		}
+		if seeded {
+			s.postgres.AppendStockLedger(ctx, models.StockLedgerEntry{
+				PrizeID:    prize.ID,
+				Kind:       models.StockLedgerSeed,
+				Change:     stock,
+				StockAfter: stock,
+				Reason:     reason,
+				Timestamp:  time.Now(),
+			})
+		}
+	}
+	return nil
+}
+
+// ReconcileStocks compares every counted prize's Redis stock with the stock
+// ledger and spin logs. With rebuild, prizes that disagree have their Redis
+// counter set to the expected stock.
+func (s *LotteryService) ReconcileStocks(ctx context.Context, rebuild bool, actor string) ([]models.StockReconciliation, error) {
+	results := []models.StockReconciliation{}
+	for _, prize := range s.countedPrizes() {
+		rec, err := s.reconcilePrize(ctx, prize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconcile %s: %w", prize.ID, err)
+		}
+
+		if rebuild && rec.Discrepancy {
+			target := max(rec.Expected, 0)
+			before, after, err := s.redis.AdjustStock(ctx, prize.ID, target, false)
+			if err != nil {
+				return nil, err
+			}
+			s.postgres.AppendStockLedger(ctx, models.StockLedgerEntry{
+				PrizeID:    prize.ID,
+				Kind:       models.StockLedgerAdjust,
+				Change:     after - before,
+				StockAfter: after,
+				Reason:     reconcileReason,
+				Actor:      actor,
+				Timestamp:  time.Now(),
+			})
+			s.live.PublishAsync(models.EventStockChanged, models.StockChange{PrizeID: prize.ID, Kind: models.StockLedgerAdjust, Change: after - before, Stock: after})
+			rec.Rebuilt = true
+		}
+
+		results = append(results, *rec)
+	}
+
+	for _, prize := range s.serialPoolPrizes() {
+		rec, err := s.reconcileSerialPool(ctx, prize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconcile %s: %w", prize.ID, err)
+		}
+
+		// Assigned serials cannot be taken back, so a rebuild only records the
+		// awards missing from the ledger (or restores ones without a spin)
+		if rebuild && rec.Discrepancy {
+			change, kind := rec.Expected-rec.LedgerStock, models.StockLedgerAward
+			if change > 0 {
+				kind = models.StockLedgerRestore
+			}
+			s.postgres.AppendStockLedger(ctx, models.StockLedgerEntry{
+				PrizeID:    prize.ID,
+				Kind:       kind,
+				Change:     change,
+				StockAfter: rec.PoolLeft,
+				Reason:     reconcileReason,
+				Actor:      actor,
+				Timestamp:  time.Now(),
+			})
+			rec.Rebuilt = true
+		}
+
+		results = append(results, *rec)
+	}
+	return results, nil
+}
+
+// reconcileSerialPool compares a serial pool's ledger in the campaign's current
+// event with the spins there that were assigned a serial
+func (s *LotteryService) reconcileSerialPool(ctx context.Context, prize config.Prize) (*models.StockReconciliation, error) {
+	available, total, err := s.postgres.GetSerialPoolCounts(ctx, prize.ID)
+	if err != nil {
+		return nil, err
+	}
+	change, awards, err := s.postgres.GetStockLedgerSince(ctx, prize.ID, 0)
+	if err != nil {
+		return nil, err
+	}
+	spins, err := s.postgres.CountSerialSpins(ctx, prize.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := serialPoolReconciliation(prize.ID, change, awards, spins)
+	rec.PoolTotal, rec.PoolLeft = total, available
+	return rec, nil
+}
+
+// serialPoolReconciliation works out a serial pool's reconciliation from its
+// ledger and spins. Every spin assigned a serial should have an award entry, so
+// the expected ledger stock is the ledger stock with the awards it is missing.
+// The pool's own counts cover every event and are for information only.
+func serialPoolReconciliation(prizeID string, ledgerChange, ledgerAwards, serialSpins int) *models.StockReconciliation {
+	rec := &models.StockReconciliation{
+		PrizeID:     prizeID,
+		SerialPool:  true,
+		LedgerStock: ledgerChange,
+		Expected:    ledgerChange - (serialSpins - ledgerAwards),
+		SpinsSince:  serialSpins,
+		AwardsSince: ledgerAwards,
+	}
+	rec.Discrepancy = serialSpins != ledgerAwards
+	return rec
+}
+
+// reconcilePrize works out what a prize's stock should be. The baseline is the
+// last seed, reset or adjust entry (or the configured stock if there is none);
+// the expected stock subtracts the spins logged for the prize since then, and
+// the ledger stock adds the ledger changes since then.
+func (s *LotteryService) reconcilePrize(ctx context.Context, prize config.Prize) (*models.StockReconciliation, error) {
+	var redisStock *int
+	exists, err := s.redis.StockExists(ctx, prize.ID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		stock, err := s.redis.GetStock(ctx, prize.ID)
+		if err != nil {
+			return nil, err
+		}
+		redisStock = &stock
+	}
+
+	anchor, err := s.postgres.GetStockLedgerAnchor(ctx, prize.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	baseline, since, afterID := prize.Stock, time.Time{}, int64(0)
+	if anchor != nil {
+		baseline, since, afterID = anchor.StockAfter, anchor.Timestamp, anchor.ID
+	}
+
+	change, awards, err := s.postgres.GetStockLedgerSince(ctx, prize.ID, afterID)
+	if err != nil {
+		return nil, err
+	}
+	spins, err := s.postgres.CountPrizeSpinsSince(ctx, prize.ID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := stockReconciliation(prize.ID, redisStock, baseline, change, awards, spins)
+	if anchor != nil {
+		rec.AnchorKind = anchor.Kind
+		rec.AnchorAt = &anchor.Timestamp
+	}
+	return rec, nil
+}
+
+// stockReconciliation works out a counted prize's reconciliation from its
+// baseline and what happened since. Redis, the ledger and the spin logs must
+// all agree; a missing Redis counter is always a discrepancy.
+func stockReconciliation(prizeID string, redisStock *int, baseline, ledgerChange, ledgerAwards, spins int) *models.StockReconciliation {
+	rec := &models.StockReconciliation{
+		PrizeID:     prizeID,
+		RedisStock:  redisStock,
+		LedgerStock: baseline + ledgerChange,
+		Expected:    baseline - spins,
+		SpinsSince:  spins,
+		AwardsSince: ledgerAwards,
+	}
+	rec.Discrepancy = redisStock == nil || *redisStock != rec.Expected || rec.LedgerStock != rec.Expected
+	return rec
+}
+
+// notifyStockLevel sends a stock.sold_out webhook when a prize's stock reaches
+// zero, or stock.low when it reaches the prize's threshold
+func (s *LotteryService) notifyStockLevel(prizeID string, stock int, stationID string) {
+	prize, ok := s.getPrize(prizeID)
+	if !ok || !prize.IsLimited() {
+		return
+	}
+
+	alert := models.StockAlert{
+		PrizeID:   prize.ID,
+		PrizeName: prize.Name,
+		Stock:     stock,
+		Threshold: prize.LowStockThreshold,
+		StationID: stationID,
+	}
+	switch {
+	case stock == 0:
+		s.publish(models.EventStockSoldOut, alert)
+	case stock == prize.LowStockThreshold:
+		s.publish(models.EventStockLow, alert)
+	}
+}
+
+// notifySerialPoolLevel checks a serial pool prize's remaining serials after a win
+func (s *LotteryService) notifySerialPoolLevel(ctx context.Context, prize config.Prize, stationID string) {
+	available, _, err := s.postgres.GetSerialPoolCounts(ctx, prize.ID)
+	if err != nil {
+		fmt.Printf("Failed to check serial pool: %v\n", err)
+		return
+	}
+	s.recordStockChange(prize.ID, models.StockLedgerAward, -1, int64(available), stationID)
+	s.notifyStockLevel(prize.ID, available, stationID)
+}
+
+// notifyLockFallback sends a lock.fallback webhook when a locked prize was out
+// of stock and the spin fell back to random
+func (s *LotteryService) notifyLockFallback(prizeID, stationID string) {
+	s.publish(models.EventLockFallback, models.StockAlert{
+		PrizeID:   prizeID,
+		PrizeName: s.getPrizeName(prizeID),
+		StationID: stationID,
+	})
+}
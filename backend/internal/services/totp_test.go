@@ -0,0 +1,78 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 appendix B test vectors,
+// "12345678901234567890", base32-encoded
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// rfc6238Vectors are the appendix B SHA-1 values truncated to six digits
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	for _, v := range rfc6238Vectors {
+		step := v.unix / int64(totpPeriod.Seconds())
+		code, err := totpCode(rfc6238Secret, step)
+		if err != nil {
+			t.Fatalf("totpCode(%d): %v", v.unix, err)
+		}
+		if code != v.code {
+			t.Errorf("totpCode at %d = %s, want %s", v.unix, code, v.code)
+		}
+	}
+}
+
+func TestVerifyTOTPRFC6238(t *testing.T) {
+	for _, v := range rfc6238Vectors {
+		now := time.Unix(v.unix, 0)
+		step, ok := verifyTOTP(rfc6238Secret, v.code, now)
+		if !ok {
+			t.Errorf("verifyTOTP rejected %s at %d", v.code, v.unix)
+			continue
+		}
+		if want := v.unix / int64(totpPeriod.Seconds()); step != want {
+			t.Errorf("verifyTOTP at %d matched step %d, want %d", v.unix, step, want)
+		}
+	}
+}
+
+func TestVerifyTOTPSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code := "005924"
+
+	for _, offset := range []time.Duration{-totpPeriod, totpPeriod} {
+		if _, ok := verifyTOTP(rfc6238Secret, code, now.Add(offset)); !ok {
+			t.Errorf("verifyTOTP rejected a code one step away (%v)", offset)
+		}
+	}
+	for _, offset := range []time.Duration{-3 * totpPeriod, 3 * totpPeriod} {
+		if _, ok := verifyTOTP(rfc6238Secret, code, now.Add(offset)); ok {
+			t.Errorf("verifyTOTP accepted a code three steps away (%v)", offset)
+		}
+	}
+}
+
+func TestVerifyTOTPRejectsMalformed(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	for _, code := range []string{"", "00592", "0059240", "abcdef", "005925"} {
+		if _, ok := verifyTOTP(rfc6238Secret, code, now); ok {
+			t.Errorf("verifyTOTP accepted %q", code)
+		}
+	}
+	if _, ok := verifyTOTP("not base32!", "005924", now); ok {
+		t.Error("verifyTOTP accepted a code for an invalid secret")
+	}
+}
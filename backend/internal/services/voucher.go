@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/repository"
+)
+
+const (
+	// voucherAlphabet is Crockford's base32: no I, L, O or U, so codes survive being read aloud
+	voucherAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+	// voucherDataLength is the number of random characters before the check character
+	voucherDataLength = 8
+
+	// voucherIssueAttempts bounds retries when a random code collides
+	voucherIssueAttempts = 5
+)
+
+var (
+	ErrVoucherInvalid  = errors.New("voucher code is malformed or has a wrong check digit")
+	ErrVoucherNotFound = errors.New("voucher not found")
+	ErrVoucherRedeemed = errors.New("voucher has already been redeemed")
+	ErrVoucherVoid     = errors.New("voucher has been voided")
+)
+
+// VoucherService looks up, redeems and voids winners' voucher codes
+type VoucherService struct {
+	postgres *repository.PostgresRepository
+}
+
+// NewVoucherService creates a new voucher service
+func NewVoucherService(postgres *repository.PostgresRepository) *VoucherService {
+	return &VoucherService{postgres: postgres}
+}
+
+// Lookup returns a voucher by code, accepting lowercase, dashes and look-alike characters
+func (s *VoucherService) Lookup(ctx context.Context, code string) (*models.Voucher, error) {
+	normalized, err := normalizeVoucherCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	voucher, err := s.postgres.GetVoucher(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+	if voucher == nil {
+		return nil, ErrVoucherNotFound
+	}
+	return voucher, nil
+}
+
+// Redeem marks a voucher as used. Each voucher can only be redeemed once.
+func (s *VoucherService) Redeem(ctx context.Context, code, redeemedBy string) (*models.Voucher, error) {
+	normalized, err := normalizeVoucherCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	redeemed, err := s.postgres.RedeemVoucher(ctx, normalized, redeemedBy)
+	if err != nil {
+		return nil, err
+	}
+	return s.afterUpdate(ctx, normalized, redeemed)
+}
+
+// Void cancels a voucher that has not been redeemed
+func (s *VoucherService) Void(ctx context.Context, code, reason string) (*models.Voucher, error) {
+	normalized, err := normalizeVoucherCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	voided, err := s.postgres.VoidVoucher(ctx, normalized, reason)
+	if err != nil {
+		return nil, err
+	}
+	return s.afterUpdate(ctx, normalized, voided)
+}
+
+// afterUpdate reloads a voucher, explaining why the update was refused if it was
+func (s *VoucherService) afterUpdate(ctx context.Context, code string, updated bool) (*models.Voucher, error) {
+	voucher, err := s.postgres.GetVoucher(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if voucher == nil {
+		return nil, ErrVoucherNotFound
+	}
+	if updated {
+		return voucher, nil
+	}
+
+	switch voucher.Status {
+	case models.VoucherRedeemed:
+		return voucher, ErrVoucherRedeemed
+	case models.VoucherVoid:
+		return voucher, ErrVoucherVoid
+	default:
+		return voucher, fmt.Errorf("voucher in unexpected status %q", voucher.Status)
+	}
+}
+
+// generateVoucherCode returns random characters followed by a Luhn mod 32
+// check character, formatted as XXX-XXX-XXX
+func generateVoucherCode() (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(voucherAlphabet)))
+	for i := 0; i < voucherDataLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate voucher code: %w", err)
+		}
+		b.WriteByte(voucherAlphabet[n.Int64()])
+	}
+
+	data := b.String()
+	return formatVoucherCode(data + string(voucherAlphabet[luhnCheck(data)])), nil
+}
+
+// formatVoucherCode groups a 9 character code as XXX-XXX-XXX
+func formatVoucherCode(code string) string {
+	return code[0:3] + "-" + code[3:6] + "-" + code[6:9]
+}
+
+// maskVoucherCode hides all but the last group of a stored code, so a listing
+// identifies a voucher without making it redeemable: "K7M-2P9-QXD" becomes "***-***-QXD"
+func maskVoucherCode(code string) string {
+	if code == "" {
+		return ""
+	}
+	if len(code) <= 3 {
+		return "***"
+	}
+	return "***-***-" + code[len(code)-3:]
+}
+
+// normalizeVoucherCode uppercases a typed code, maps look-alike characters,
+// verifies the check character and returns it in the stored XXX-XXX-XXX form
+func normalizeVoucherCode(code string) (string, error) {
+	replacer := strings.NewReplacer("-", "", " ", "", "O", "0", "I", "1", "L", "1")
+	normalized := replacer.Replace(strings.ToUpper(strings.TrimSpace(code)))
+
+	if len(normalized) != voucherDataLength+1 {
+		return "", ErrVoucherInvalid
+	}
+	for _, ch := range normalized {
+		if !strings.ContainsRune(voucherAlphabet, ch) {
+			return "", ErrVoucherInvalid
+		}
+	}
+
+	data, check := normalized[:voucherDataLength], normalized[voucherDataLength]
+	if voucherAlphabet[luhnCheck(data)] != check {
+		return "", ErrVoucherInvalid
+	}
+	return formatVoucherCode(normalized), nil
+}
+
+// luhnCheck computes the Luhn mod N check value for data, catching every
+// single-character typo and most adjacent transpositions
+func luhnCheck(data string) int {
+	n := len(voucherAlphabet)
+	factor := 2
+	sum := 0
+	for i := len(data) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(voucherAlphabet, data[i])
+		addend = addend/n + addend%n
+		sum += addend
+		if factor == 2 {
+			factor = 1
+		} else {
+			factor = 2
+		}
+	}
+	return (n - sum%n) % n
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLuhnCheck(t *testing.T) {
+	// Expected values from the Luhn mod N algorithm over Crockford's base32
+	tests := []struct {
+		data  string
+		check byte
+	}{
+		{"00000000", '0'},
+		{"12345678", '8'},
+		{"ABCDEFGH", 'V'},
+		{"ZZZZZZZZ", '8'},
+		{"K7M2P9QX", 'D'},
+	}
+	for _, tt := range tests {
+		if got := voucherAlphabet[luhnCheck(tt.data)]; got != tt.check {
+			t.Errorf("luhnCheck(%q) = %c, want %c", tt.data, got, tt.check)
+		}
+	}
+}
+
+func TestLuhnCheckCatchesTypos(t *testing.T) {
+	data := "K7M2P9QX"
+	check := luhnCheck(data)
+
+	// Every single-character substitution changes the check character
+	for i := range data {
+		for _, ch := range voucherAlphabet {
+			if byte(ch) == data[i] {
+				continue
+			}
+			typo := data[:i] + string(ch) + data[i+1:]
+			if luhnCheck(typo) == check {
+				t.Errorf("luhnCheck did not catch %q typed as %q", data, typo)
+			}
+		}
+	}
+}
+
+func TestNormalizeVoucherCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"K7M-2P9-QXD", "K7M-2P9-QXD"},
+		{"k7m2p9qxd", "K7M-2P9-QXD"},
+		{"  k7m 2p9 qxd ", "K7M-2P9-QXD"},
+		{"ooo-ooo-oo0", "000-000-000"},
+		{"123-456-788", "123-456-788"},
+		{"i23-456-788", "123-456-788"},
+		{"l23-456-788", "123-456-788"},
+	}
+	for _, tt := range tests {
+		got, err := normalizeVoucherCode(tt.code)
+		if err != nil {
+			t.Errorf("normalizeVoucherCode(%q) failed: %v", tt.code, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("normalizeVoucherCode(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeVoucherCodeRejects(t *testing.T) {
+	for _, code := range []string{
+		"",
+		"K7M-2P9-QX",   // too short
+		"K7M-2P9-QXDD", // too long
+		"K7M-2P9-QXE",  // wrong check character
+		"K7M-2P9-UXD",  // U is not in the alphabet
+		"K7M-2P9-QX!",
+	} {
+		if _, err := normalizeVoucherCode(code); !errors.Is(err, ErrVoucherInvalid) {
+			t.Errorf("normalizeVoucherCode(%q) error = %v, want ErrVoucherInvalid", code, err)
+		}
+	}
+}
+
+func TestMaskVoucherCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"K7M-2P9-QXD", "***-***-QXD"},
+		{"", ""},
+		{"AB", "***"},
+	}
+	for _, tt := range tests {
+		if got := maskVoucherCode(tt.code); got != tt.want {
+			t.Errorf("maskVoucherCode(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateVoucherCode(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		code, err := generateVoucherCode()
+		if err != nil {
+			t.Fatalf("generateVoucherCode: %v", err)
+		}
+		if len(code) != 11 || strings.Count(code, "-") != 2 {
+			t.Fatalf("generateVoucherCode() = %q, want XXX-XXX-XXX", code)
+		}
+		normalized, err := normalizeVoucherCode(code)
+		if err != nil || normalized != code {
+			t.Fatalf("generated code %q does not normalize to itself: %q, %v", code, normalized, err)
+		}
+	}
+}
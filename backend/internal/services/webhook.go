@@ -0,0 +1,328 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ChaiyawutTar/pungdip/backend/internal/config"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/models"
+	"github.com/ChaiyawutTar/pungdip/backend/internal/repository"
+)
+
+const (
+	// Headers sent with every webhook, see SignWebhook
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+
+	// webhookPollInterval is how often the sender looks for due deliveries
+	webhookPollInterval = time.Second
+
+	// webhookBatchSize bounds how many deliveries are sent per poll
+	webhookBatchSize = 20
+
+	// webhookMaxRetryDelay caps the exponential backoff between attempts
+	webhookMaxRetryDelay = time.Hour
+)
+
+var (
+	ErrWebhookNotConfigured    = errors.New("no webhook receiver configured, set WEBHOOK_URL or add a subscription")
+	ErrWebhookURLInvalid       = errors.New("webhook URL must be an absolute http or https URL")
+	ErrWebhookEventTypeInvalid = errors.New("event_types must list at least one known event type")
+	ErrSubscriptionNotFound    = errors.New("webhook subscription not found or already deleted")
+	ErrDeliveryNotRetryable    = errors.New("only failed deliveries of active receivers can be retried")
+)
+
+// WebhookService queues events in Postgres for the WEBHOOK_URL receiver and
+// matching subscriptions, and sends them with at-least-once delivery: failed
+// attempts are retried with exponential backoff until they run out, then left
+// as failed deliveries (the dead letters) for an admin to retry
+type WebhookService struct {
+	config   *config.Config
+	postgres *repository.PostgresRepository
+	client   *http.Client
+	wake     chan struct{}
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(cfg *config.Config, postgres *repository.PostgresRepository) *WebhookService {
+	return &WebhookService{
+		config:   cfg,
+		postgres: postgres,
+		client:   &http.Client{Timeout: cfg.WebhookTimeout},
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// SignWebhook returns the X-Webhook-Signature value for a body: "sha256=" and
+// the hex HMAC-SHA256 of the timestamp, a dot and the body
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// PublishAsync queues an event for every receiver of its type without
+// blocking the caller
+func (s *WebhookService) PublishAsync(eventType string, data interface{}) {
+	go func() {
+		ctx := context.Background()
+		subs, err := s.postgres.ListWebhookSubscriptions(ctx, eventType)
+		if err != nil {
+			fmt.Printf("Failed to publish webhook %s: %v\n", eventType, err)
+			return
+		}
+
+		if _, err := s.publish(ctx, eventType, data, subs, models.IsStockAlert(eventType)); err != nil {
+			fmt.Printf("Failed to publish webhook %s: %v\n", eventType, err)
+		}
+	}()
+}
+
+// SendTest queues a webhook.test event for the WEBHOOK_URL receiver and every
+// subscription, whatever event types they chose
+func (s *WebhookService) SendTest(ctx context.Context) (*models.WebhookEvent, error) {
+	subs, err := s.postgres.ListWebhookSubscriptions(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if s.config.WebhookURL == "" && len(subs) == 0 {
+		return nil, ErrWebhookNotConfigured
+	}
+	return s.publish(ctx, models.EventWebhookTest, map[string]string{"message": "Webhook test from Pangdip Lucky Draw"}, subs, true)
+}
+
+// Subscribe registers a URL for some event types. The signing secret is
+// generated if empty and only returned here.
+func (s *WebhookService) Subscribe(ctx context.Context, rawURL string, eventTypes []string, signingSecret string) (*models.WebhookSubscriptionRegistration, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, ErrWebhookURLInvalid
+	}
+
+	if len(eventTypes) == 0 {
+		return nil, ErrWebhookEventTypeInvalid
+	}
+	for _, eventType := range eventTypes {
+		if !slices.Contains(models.WebhookEventTypes, eventType) {
+			return nil, fmt.Errorf("%w: %q", ErrWebhookEventTypeInvalid, eventType)
+		}
+	}
+
+	if signingSecret == "" {
+		if signingSecret, err = randomHex(32); err != nil {
+			return nil, fmt.Errorf("failed to generate signing secret: %w", err)
+		}
+	}
+
+	sub := models.WebhookSubscription{
+		URL:        rawURL,
+		EventTypes: eventTypes,
+		Secret:     signingSecret,
+		CreatedAt:  time.Now(),
+	}
+	if sub.ID, err = s.postgres.CreateWebhookSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	return &models.WebhookSubscriptionRegistration{Subscription: sub, SigningSecret: signingSecret}, nil
+}
+
+// ListSubscriptions returns the active subscriptions
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	return s.postgres.ListWebhookSubscriptions(ctx, "")
+}
+
+// Unsubscribe deletes a subscription; its pending deliveries are marked failed
+func (s *WebhookService) Unsubscribe(ctx context.Context, id int64) error {
+	deleted, err := s.postgres.DeleteWebhookSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// ListDeliveries returns recent deliveries, optionally only those in a status.
+// Failed deliveries are the dead letters.
+func (s *WebhookService) ListDeliveries(ctx context.Context, status string, limit int) ([]models.WebhookDelivery, error) {
+	return s.postgres.ListWebhookDeliveries(ctx, status, limit)
+}
+
+// RetryDelivery queues a failed delivery again with a fresh set of attempts
+func (s *WebhookService) RetryDelivery(ctx context.Context, id int64) error {
+	retried, err := s.postgres.RetryWebhookDelivery(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !retried {
+		return ErrDeliveryNotRetryable
+	}
+
+	s.wakeSender()
+	return nil
+}
+
+// publish stores one delivery of an event per subscription, plus one for the
+// WEBHOOK_URL receiver if toReceiver is set, and wakes the sender
+func (s *WebhookService) publish(ctx context.Context, eventType string, data interface{}, subs []models.WebhookSubscription, toReceiver bool) (*models.WebhookEvent, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate event ID: %w", err)
+	}
+
+	event := &models.WebhookEvent{
+		ID:        id,
+		Type:      eventType,
+		CreatedAt: time.Now(),
+		Data:      data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if toReceiver && s.config.WebhookURL != "" {
+		deliveries = append(deliveries, models.WebhookDelivery{URL: s.config.WebhookURL})
+	}
+	for i := range subs {
+		deliveries = append(deliveries, models.WebhookDelivery{URL: subs[i].URL, SubscriptionID: &subs[i].ID})
+	}
+
+	for _, delivery := range deliveries {
+		delivery.EventID = event.ID
+		delivery.EventType = event.Type
+		delivery.Payload = payload
+		delivery.CreatedAt = event.CreatedAt
+		if err := s.postgres.CreateWebhookDelivery(ctx, delivery); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(deliveries) > 0 {
+		s.wakeSender()
+	}
+	return event, nil
+}
+
+// wakeSender makes Run check for due deliveries now instead of at the next tick
+func (s *WebhookService) wakeSender() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run sends due deliveries until ctx is cancelled. Deliveries are persisted,
+// so events queued before a restart are still sent.
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+		s.sendDue(ctx)
+	}
+}
+
+// sendDue sends every delivery that is due, a batch at a time
+func (s *WebhookService) sendDue(ctx context.Context) {
+	for {
+		// Lease for longer than an attempt can take, so nothing else sends it meanwhile
+		deliveries, err := s.postgres.ClaimDueWebhookDeliveries(ctx, webhookBatchSize, time.Now().Add(2*s.config.WebhookTimeout))
+		if err != nil {
+			fmt.Printf("Failed to fetch webhooks: %v\n", err)
+			return
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+
+		for _, delivery := range deliveries {
+			s.attempt(ctx, delivery)
+		}
+	}
+}
+
+// attempt sends one delivery and records the outcome, scheduling a retry on failure
+func (s *WebhookService) attempt(ctx context.Context, delivery models.WebhookDelivery) {
+	statusCode, err := s.send(ctx, delivery)
+
+	status, lastError, next := models.DeliveryDelivered, "", time.Now()
+	if err != nil {
+		lastError = err.Error()
+		status = models.DeliveryPending
+		next = time.Now().Add(s.retryDelay(delivery.Attempts + 1))
+		if delivery.Attempts+1 >= s.config.WebhookMaxAttempts {
+			status = models.DeliveryFailed
+		}
+	}
+
+	if err := s.postgres.RecordWebhookAttempt(ctx, delivery.ID, status, statusCode, lastError, next); err != nil {
+		fmt.Printf("Failed to record webhook attempt: %v\n", err)
+	}
+}
+
+// send posts a delivery's payload; any non-2xx response is an error
+func (s *WebhookService) send(ctx context.Context, delivery models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.EventID)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	secret := s.config.WebhookSecret
+	if delivery.SubscriptionID != nil {
+		secret = delivery.Secret
+	}
+	if secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(secret, timestamp, delivery.Payload))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryDelay returns the wait before the given attempt number, doubling each time
+func (s *WebhookService) retryDelay(attempt int) time.Duration {
+	delay := s.config.WebhookRetryBase
+	for i := 1; i < attempt && delay < webhookMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookMaxRetryDelay)
+}
@@ -2,6 +2,7 @@ import { BrowserRouter, Routes, Route } from 'react-router-dom';
 import { QueryClient, QueryClientProvider } from '@tanstack/react-query';
 import { PublicGame } from './pages/PublicGame';
 import { AdminPanel } from './pages/AdminPanel';
+import { WinnerDisplay } from './pages/WinnerDisplay';
 
 const queryClient = new QueryClient({
   defaultOptions: {
@@ -19,6 +20,7 @@ function App() {
         <Routes>
           <Route path="/" element={<PublicGame />} />
           <Route path="/secret-admin-control" element={<AdminPanel />} />
+          <Route path="/display" element={<WinnerDisplay />} />
         </Routes>
       </BrowserRouter>
     </QueryClientProvider>
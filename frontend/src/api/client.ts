@@ -4,11 +4,78 @@ import type {
     APIResponse,
     SpinLog,
     AdminStatus,
-    Prize
+    Prize,
+    PublicPrize,
+    WheelLayout,
+    EventStatus,
+    BoothEvent,
+    PlayerProfile
 } from '../types';
 
 const API_BASE_URL = import.meta.env.VITE_API_URL || 'http://localhost:8080';
 
+// Kiosk API key and signing secret issued by POST /api/admin/stations. They are
+// provisioned on each kiosk and never built into the bundle: opening the kiosk
+// once at /#station_key=<key>&signing_secret=<secret> keeps them in localStorage.
+const STATION_KEY_STORAGE = 'pangdip:station_key';
+const SIGNING_SECRET_STORAGE = 'pangdip:signing_secret';
+
+const provisionStation = (): void => {
+    const params = new URLSearchParams(window.location.hash.slice(1));
+    const key = params.get('station_key');
+    const secret = params.get('signing_secret');
+    if (!key || !secret) {
+        return;
+    }
+
+    localStorage.setItem(STATION_KEY_STORAGE, key);
+    localStorage.setItem(SIGNING_SECRET_STORAGE, secret);
+    // Keep the credentials out of the address bar and history
+    window.history.replaceState(null, '', window.location.pathname + window.location.search);
+};
+provisionStation();
+
+const STATION_KEY = localStorage.getItem(STATION_KEY_STORAGE) || '';
+const STATION_SIGNING_SECRET = localStorage.getItem(SIGNING_SECRET_STORAGE) || '';
+
+// Campaign this kiosk spins, from GET /api/campaigns; empty for the default wheel
+const CAMPAIGN = import.meta.env.VITE_CAMPAIGN || '';
+const publicPath = (path: string): string =>
+    CAMPAIGN ? `/api/campaigns/${encodeURIComponent(CAMPAIGN)}${path}` : `/api${path}`;
+
+const toHex = (buf: ArrayBuffer): string =>
+    Array.from(new Uint8Array(buf))
+        .map((b) => b.toString(16).padStart(2, '0'))
+        .join('');
+
+// Signs a kiosk request: HMAC-SHA256 over timestamp, nonce, method, path and body hash
+const signRequest = async (method: string, path: string, body: string): Promise<Record<string, string>> => {
+    if (!STATION_SIGNING_SECRET) {
+        return {};
+    }
+
+    const encoder = new TextEncoder();
+    const timestamp = Math.floor(Date.now() / 1000).toString();
+    const nonce = crypto.randomUUID();
+    const bodyHash = toHex(await crypto.subtle.digest('SHA-256', encoder.encode(body)));
+    const payload = [timestamp, nonce, method, path, bodyHash].join('\n');
+
+    const key = await crypto.subtle.importKey(
+        'raw',
+        encoder.encode(STATION_SIGNING_SECRET),
+        { name: 'HMAC', hash: 'SHA-256' },
+        false,
+        ['sign'],
+    );
+    const signature = toHex(await crypto.subtle.sign('HMAC', key, encoder.encode(payload)));
+
+    return {
+        'X-Signature': signature,
+        'X-Signature-Timestamp': timestamp,
+        'X-Signature-Nonce': nonce,
+    };
+};
+
 const api: AxiosInstance = axios.create({
     baseURL: API_BASE_URL,
     headers: {
@@ -18,25 +85,121 @@ const api: AxiosInstance = axios.create({
 
 // Public API
 export const spin = async (instagramId: string): Promise<SpinResult> => {
-    const response = await api.post<SpinResult>('/api/spin', {
-        instagram_id: instagramId,
+    // Send the exact bytes that were signed
+    const body = JSON.stringify({ instagram_id: instagramId });
+
+    // Retries reuse the Idempotency-Key so flaky Wi-Fi cannot spin twice,
+    // but are re-signed because every nonce may only be used once
+    const idempotencyKey = crypto.randomUUID();
+    const maxAttempts = 3;
+    for (let attempt = 1; ; attempt++) {
+        try {
+            const signatureHeaders = await signRequest('POST', publicPath('/spin'), body);
+            const response = await api.post<SpinResult>(publicPath('/spin'), body, {
+                headers: {
+                    'X-Station-Key': STATION_KEY,
+                    'Idempotency-Key': idempotencyKey,
+                    ...signatureHeaders,
+                },
+            });
+            return response.data;
+        } catch (err) {
+            const status = axios.isAxiosError(err) ? err.response?.status : undefined;
+            const retryable = status === undefined || status === 409;
+            if (!retryable || attempt >= maxAttempts) {
+                throw err;
+            }
+            await new Promise((resolve) => setTimeout(resolve, 500 * attempt));
+        }
+    }
+};
+
+export const getWheel = async (): Promise<WheelLayout> => {
+    const response = await api.get<APIResponse<WheelLayout>>(publicPath('/wheel'));
+    return response.data.data ?? { segments: [] };
+};
+
+export const getEvent = async (): Promise<EventStatus> => {
+    const response = await api.get<APIResponse<EventStatus>>('/api/event');
+    return response.data.data!;
+};
+
+// Public SSE feed of masked winner announcements for the big-screen display
+export const displayFeedUrl = `${API_BASE_URL}${publicPath('/display/feed')}`;
+
+// WebSocket URL for the kiosk remote control channel, or null without a station key
+export const kioskSocketUrl = (): string | null => {
+    if (!STATION_KEY) {
+        return null;
+    }
+    const url = new URL('/api/kiosk/ws', API_BASE_URL);
+    url.protocol = url.protocol === 'https:' ? 'wss:' : 'ws:';
+    url.searchParams.set('key', STATION_KEY);
+    if (CAMPAIGN) {
+        url.searchParams.set('campaign', CAMPAIGN);
+    }
+    return url.toString();
+};
+
+export const claimQRCodeUrl = (claimId: string): string =>
+    `${API_BASE_URL}/api/claims/${encodeURIComponent(claimId)}/qr`;
+
+export const getPublicPrizes = async (lang: string = 'th'): Promise<PublicPrize[]> => {
+    const response = await api.get<APIResponse<PublicPrize[]>>(publicPath('/prizes'), {
+        params: { lang },
     });
-    return response.data;
+    return response.data.data || [];
 };
 
 // Admin API
 const ADMIN_SECRET = import.meta.env.VITE_ADMIN_SECRET || 'admin_password';
 
-export const lockPrize = async (prizeId: string): Promise<APIResponse> => {
+// Session token from login, sent on admin requests and the admin stream
+let sessionToken = '';
+
+export const login = async (secret: string): Promise<APIResponse> => {
+    const response = await api.post<APIResponse<{ token: string }>>('/api/admin/login', {
+        secret,
+    }, {
+        validateStatus: () => true,
+    });
+    if (response.data.success && response.data.data) {
+        sessionToken = response.data.data.token;
+        api.defaults.headers.common['Authorization'] = `Bearer ${sessionToken}`;
+    }
+    return response.data;
+};
+
+// Without a station ID the global lock is set, used by stations without their own
+export const lockPrize = async (prizeId: string, stationId: string = ''): Promise<APIResponse> => {
     const response = await api.post<APIResponse>('/api/admin/lock', {
         prize_id: prizeId,
+        station_id: stationId,
         secret: ADMIN_SECRET,
     });
     return response.data;
 };
 
-export const unlockPrize = async (): Promise<APIResponse> => {
+export const unlockPrize = async (stationId: string = ''): Promise<APIResponse> => {
     const response = await api.post<APIResponse>('/api/admin/unlock', {
+        station_id: stationId,
+        secret: ADMIN_SECRET,
+    });
+    return response.data;
+};
+
+// resumeIn is a duration such as "15m"; without it the booth stays paused until resumed
+export const pauseBooth = async (message: string, resumeIn: string = ''): Promise<APIResponse> => {
+    const response = await api.post<APIResponse>('/api/admin/pause', {
+        message,
+        resume_in: resumeIn,
+        secret: ADMIN_SECRET,
+    });
+    return response.data;
+};
+
+export const resumeBooth = async (): Promise<APIResponse> => {
+    const response = await api.post<APIResponse>('/api/admin/resume', {
         secret: ADMIN_SECRET,
     });
     return response.data;
@@ -49,6 +212,26 @@ export const resetStocks = async (): Promise<APIResponse> => {
     return response.data;
 };
 
+export const getEvents = async (): Promise<BoothEvent[]> => {
+    const response = await api.get<APIResponse<BoothEvent[]>>('/api/admin/events');
+    return response.data.data || [];
+};
+
+// Archives the current event and starts a fresh one with full stock
+export const startEvent = async (id: string, name: string): Promise<APIResponse> => {
+    const response = await api.post<APIResponse>('/api/admin/events', {
+        id,
+        name,
+        secret: ADMIN_SECRET,
+    });
+    return response.data;
+};
+
+export const getPlayer = async (instagramId: string): Promise<PlayerProfile> => {
+    const response = await api.get<APIResponse<PlayerProfile>>(`/api/admin/players/${encodeURIComponent(instagramId)}`);
+    return response.data.data!;
+};
+
 export const getLogs = async (limit: number = 50): Promise<SpinLog[]> => {
     const response = await api.get<APIResponse<SpinLog[]>>('/api/admin/logs', {
         params: { limit },
@@ -66,6 +249,17 @@ export const getStats = async (): Promise<Record<string, unknown>> => {
     return response.data.data || {};
 };
 
+// Opens the admin live feed; EventSource resumes from the last event ID on reconnect
+export const subscribeAdminStream = (onEvent: (type: string) => void): (() => void) => {
+    // EventSource cannot send headers, so the session token goes in the query
+    const url = new URL('/api/admin/stream', API_BASE_URL);
+    url.searchParams.set('token', sessionToken);
+    const source = new EventSource(url.toString());
+    const types = ['spin.completed', 'lock.set', 'lock.consumed', 'lock.cleared', 'stock.changed', 'stock.reset', 'event.started'];
+    types.forEach((type) => source.addEventListener(type, () => onEvent(type)));
+    return () => source.close();
+};
+
 export const getPrizes = async (): Promise<Prize[]> => {
     const response = await api.get<APIResponse<Prize[]>>('/api/admin/prizes');
     return response.data.data || [];
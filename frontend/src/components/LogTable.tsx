@@ -40,6 +40,7 @@ export const LogTable = ({ logs, isLoading }: LogTableProps) => {
                         <th className="rounded-tl-xl">IG</th>
                         <th>รางวัล</th>
                         <th>เวลา</th>
+                        <th>Voucher</th>
                         <th className="rounded-tr-xl">Lock</th>
                     </tr>
                 </thead>
@@ -56,6 +57,16 @@ export const LogTable = ({ logs, isLoading }: LogTableProps) => {
                                     </span>
                                 </td>
                                 <td className="text-sm text-gray-600">{formatTime(log.timestamp)}</td>
+                                <td className="font-mono text-xs">
+                                    {log.voucher_code && (
+                                        <span className={log.voucher_status === 'issued' ? 'text-pangdip-brown' : 'text-gray-400 line-through'}>
+                                            {log.voucher_code} ({log.voucher_status})
+                                        </span>
+                                    )}
+                                    {log.gift_card_serial && (
+                                        <span className="block text-gray-600">🎁 {log.gift_card_serial}</span>
+                                    )}
+                                </td>
                                 <td>
                                     {log.was_locked && (
                                         <span className="inline-flex items-center px-2 py-1 rounded-full text-xs bg-pangdip-orange/20 text-pangdip-brown">
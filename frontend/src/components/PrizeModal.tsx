@@ -1,5 +1,6 @@
 import { useEffect, useState } from 'react';
 import type { PrizeModalProps } from '../types';
+import { claimQRCodeUrl } from '../api/client';
 
 // Thai prize display info
 const PRIZE_INFO: Record<string, { emoji: string; name: string; isWin: boolean; message?: string }> = {
@@ -67,6 +68,20 @@ export const PrizeModal = ({ isOpen, onClose, prize }: PrizeModalProps) => {
                         </p>
                     )}
 
+                    {prize.voucher_code && (
+                        <p className="text-3xl font-mono font-bold tracking-widest text-pangdip-brown mb-4">
+                            {prize.voucher_code}
+                        </p>
+                    )}
+
+                    {prize.claim_id && (
+                        <img
+                            src={claimQRCodeUrl(prize.claim_id)}
+                            alt="QR สำหรับรับรางวัล"
+                            className="mx-auto mb-4 w-48 h-48"
+                        />
+                    )}
+
                     {info.message && (
                         <p className="text-lg text-pangdip-brown/70 mb-8 bg-pangdip-custard/50 p-5 rounded-xl">
                             📸 {info.message}
@@ -1,47 +1,14 @@
 import { useState, useRef, useEffect } from 'react';
-
-interface Prize {
-    id: string;
-    label: string;
-    color: string;
-    percentage: number; // UI percentage (visual size)
-}
+import type { WheelSegment } from '../types';
 
 interface SpinWheelProps {
     onSpin: () => void;
     isSpinning: boolean;
-    result: string | null;
+    segments: WheelSegment[];
+    targetAngle: number | null; // Wheel position chosen by the server
     disabled?: boolean;
 }
 
-const PRIZES: Prize[] = [
-    { id: 'NOTHING', label: 'เสียใจด้วย', color: '#95A5A6', percentage: 30 },
-    { id: 'GIVE_IG', label: 'แจก IG', color: '#E91E63', percentage: 30 },
-    { id: 'DISCOUNT_05', label: 'ลด 5%', color: '#4ECDC4', percentage: 20 },
-    { id: 'DISCOUNT_10', label: 'ลด 10%', color: '#FF6B6B', percentage: 10 },
-    { id: 'FREE_FOOD', label: 'กินฟรี', color: '#FF9800', percentage: 5 },
-    { id: 'MK_DUCK', label: 'บัตร MK', color: '#FFD700', percentage: 3 },
-    { id: 'STARBUCKS', label: 'Starbucks 1000฿', color: '#00704A', percentage: 2 },
-];
-
-// Calculate cumulative angles for each section
-const calculateAngles = (prizes: Prize[]) => {
-    let cumulative = 0;
-    return prizes.map((prize) => {
-        const startAngle = cumulative;
-        const angle = (prize.percentage / 100) * 360;
-        cumulative += angle;
-        return {
-            ...prize,
-            startAngle,
-            endAngle: cumulative,
-            angle,
-        };
-    });
-};
-
-const PRIZES_WITH_ANGLES = calculateAngles(PRIZES);
-
 // Convert degrees to radians
 const degToRad = (deg: number) => (deg * Math.PI) / 180;
 
@@ -89,7 +56,7 @@ const generateSlicePath = (
     `;
 };
 
-export const SpinWheel = ({ onSpin, isSpinning, result, disabled }: SpinWheelProps) => {
+export const SpinWheel = ({ onSpin, isSpinning, segments, targetAngle, disabled }: SpinWheelProps) => {
     const [rotation, setRotation] = useState(0);
     const [hasSpun, setHasSpun] = useState(false);
     const wheelRef = useRef<SVGGElement>(null);
@@ -100,25 +67,13 @@ export const SpinWheel = ({ onSpin, isSpinning, result, disabled }: SpinWheelPro
     const radius = 140;
     const innerRadius = 35;
 
-    // Find the rotation needed to land on the result
-    const getResultRotation = (resultId: string, currentRotation: number) => {
-        // Use exact match only
-        const prize = PRIZES_WITH_ANGLES.find((p) => p.id === resultId);
-        if (!prize) {
-            console.warn(`Prize not found: ${resultId}`);
-            return 360 * 5;
-        }
-
-        // Target the middle of the prize section
-        const targetAngle = (prize.startAngle + prize.endAngle) / 2;
-
+    // Find the rotation needed to land on the server's target angle
+    const getResultRotation = (target: number, currentRotation: number) => {
         // The pointer is at 90 degrees (right side) from the top
-        // We need to find the absolute wheel position that puts targetAngle at 90°
-        // When wheel is at 0°, section at angle A appears at position A
-        // When wheel rotates by R°, section at angle A appears at position (A + R) mod 360
-        // We want: (targetAngle + R) mod 360 = 90
-        // So R = 90 - targetAngle (mod 360)
-        const absoluteTargetPosition = (90 - targetAngle + 360) % 360;
+        // When wheel rotates by R°, the point at angle A appears at position (A + R) mod 360
+        // We want: (target + R) mod 360 = 90
+        // So R = 90 - target (mod 360)
+        const absoluteTargetPosition = (((90 - target) % 360) + 360) % 360;
 
         // Current wheel position (normalized to 0-360)
         const currentPosition = ((currentRotation % 360) + 360) % 360;
@@ -136,11 +91,11 @@ export const SpinWheel = ({ onSpin, isSpinning, result, disabled }: SpinWheelPro
     };
 
     useEffect(() => {
-        if (isSpinning && result && !spinningRef.current) {
+        if (isSpinning && targetAngle !== null && !spinningRef.current) {
             spinningRef.current = true;
-            const additionalRotation = getResultRotation(result, rotation);
+            const additionalRotation = getResultRotation(targetAngle, rotation);
             const targetRotation = rotation + additionalRotation;
-            console.log(`Spinning to ${result}: current=${rotation}, additional=${additionalRotation}, target=${targetRotation}`);
+            console.log(`Spinning to ${targetAngle}°: current=${rotation}, additional=${additionalRotation}, target=${targetRotation}`);
             setRotation(targetRotation);
             setHasSpun(true);
 
@@ -148,7 +103,12 @@ export const SpinWheel = ({ onSpin, isSpinning, result, disabled }: SpinWheelPro
                 spinningRef.current = false;
             }, 4000);
         }
-    }, [isSpinning, result]);
+    }, [isSpinning, targetAngle]);
+
+    // A prize can have several segments; list it once in the legend
+    const legend = segments.filter(
+        (segment, i) => segments.findIndex((s) => s.prize_id === segment.prize_id) === i
+    );
 
     const handleCenterClick = () => {
         if (!isSpinning && !disabled) {
@@ -206,21 +166,21 @@ export const SpinWheel = ({ onSpin, isSpinning, result, disabled }: SpinWheelPro
                         }}
                     >
                         {/* Prize slices - no text inside */}
-                        {PRIZES_WITH_ANGLES.map((prize) => {
+                        {segments.map((segment) => {
                             const path = generateSlicePath(
                                 cx,
                                 cy,
                                 radius,
-                                prize.startAngle,
-                                prize.endAngle,
+                                segment.start_angle,
+                                segment.end_angle,
                                 innerRadius
                             );
 
                             return (
                                 <path
-                                    key={prize.id}
+                                    key={segment.index}
                                     d={path}
-                                    fill={prize.color}
+                                    fill={segment.color}
                                     stroke="#fff"
                                     strokeWidth="2"
                                 />
@@ -270,14 +230,14 @@ export const SpinWheel = ({ onSpin, isSpinning, result, disabled }: SpinWheelPro
             {/* Legend - color-coded labels */}
             <div className="flex flex-col gap-2 bg-[#FFF5E6] p-4 rounded-xl">
                 <h3 className="font-bold text-pangdip-brown mb-2 text-center">รางวัล</h3>
-                {PRIZES.map((prize) => (
-                    <div key={prize.id} className="flex items-center gap-2">
+                {legend.map((segment) => (
+                    <div key={segment.prize_id} className="flex items-center gap-2">
                         <div
                             className="w-4 h-4 rounded-sm flex-shrink-0"
-                            style={{ backgroundColor: prize.color }}
+                            style={{ backgroundColor: segment.color }}
                         />
                         <span className="text-sm text-pangdip-brown font-medium">
-                            {prize.label}
+                            {segment.label}
                         </span>
                     </div>
                 ))}
@@ -0,0 +1,47 @@
+import { useEffect, useRef } from 'react';
+import { kioskSocketUrl } from '../api/client';
+import type { KioskCommand } from '../types';
+
+const RECONNECT_DELAY_MS = 3000;
+
+// Connects the kiosk to the remote control channel and acknowledges each command.
+// The handler throws to reject a command; the error message is sent back to the admin.
+export const useKioskChannel = (onCommand: (command: KioskCommand) => void) => {
+    const handlerRef = useRef(onCommand);
+    handlerRef.current = onCommand;
+
+    useEffect(() => {
+        const url = kioskSocketUrl();
+        if (!url) return;
+
+        let socket: WebSocket;
+        let retry: ReturnType<typeof setTimeout>;
+        let closed = false;
+
+        const connect = () => {
+            socket = new WebSocket(url);
+            socket.onmessage = (event) => {
+                const command = JSON.parse(event.data) as KioskCommand;
+                try {
+                    handlerRef.current(command);
+                    socket.send(JSON.stringify({ command_id: command.id, ok: true }));
+                } catch (error) {
+                    const message = error instanceof Error ? error.message : String(error);
+                    socket.send(JSON.stringify({ command_id: command.id, ok: false, error: message }));
+                }
+            };
+            socket.onclose = () => {
+                if (!closed) {
+                    retry = setTimeout(connect, RECONNECT_DELAY_MS);
+                }
+            };
+        };
+        connect();
+
+        return () => {
+            closed = true;
+            clearTimeout(retry);
+            socket.close();
+        };
+    }, []);
+};
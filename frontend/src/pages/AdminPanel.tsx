@@ -6,9 +6,13 @@ import {
     getStatus,
     getLogs,
     getPrizes,
+    login,
     lockPrize,
     unlockPrize,
-    resetStocks
+    resetStocks,
+    pauseBooth,
+    resumeBooth,
+    subscribeAdminStream
 } from '../api/client';
 import type { Prize } from '../types';
 
@@ -18,12 +22,13 @@ export const AdminPanel = () => {
     const [password, setPassword] = useState('');
     const [selectedPrize, setSelectedPrize] = useState<string>('MK_DUCK');
 
-    const handleLogin = (e: React.FormEvent) => {
+    const handleLogin = async (e: React.FormEvent) => {
         e.preventDefault();
-        if (password === 'm113') {
+        const result = await login(password);
+        if (result.success) {
             setIsAuthenticated(true);
         } else {
-            alert('รหัสผ่านไม่ถูกต้อง');
+            alert(result.message || 'รหัสผ่านไม่ถูกต้อง');
             setPassword('');
         }
     };
@@ -32,14 +37,14 @@ export const AdminPanel = () => {
     const statusQuery = useQuery({
         queryKey: ['admin', 'status'],
         queryFn: getStatus,
-        refetchInterval: 3000,
+        refetchInterval: 30000,
         enabled: isAuthenticated,
     });
 
     const logsQuery = useQuery({
         queryKey: ['admin', 'logs'],
         queryFn: () => getLogs(30),
-        refetchInterval: 5000,
+        refetchInterval: 30000,
         enabled: isAuthenticated,
     });
 
@@ -49,16 +54,41 @@ export const AdminPanel = () => {
         enabled: isAuthenticated,
     });
 
+    // Live feed refreshes the views as events arrive; polling above is only a fallback
+    useEffect(() => {
+        if (!isAuthenticated) return;
+        return subscribeAdminStream((type) => {
+            if (type === 'spin.completed') {
+                queryClient.invalidateQueries({ queryKey: ['admin', 'logs'] });
+            }
+            queryClient.invalidateQueries({ queryKey: ['admin', 'status'] });
+        });
+    }, [isAuthenticated, queryClient]);
+
     // Mutations
     const lockMutation = useMutation({
-        mutationFn: lockPrize,
+        mutationFn: (prizeId: string) => lockPrize(prizeId),
         onSuccess: () => {
             queryClient.invalidateQueries({ queryKey: ['admin', 'status'] });
         },
     });
 
     const unlockMutation = useMutation({
-        mutationFn: unlockPrize,
+        mutationFn: (stationId?: string) => unlockPrize(stationId),
+        onSuccess: () => {
+            queryClient.invalidateQueries({ queryKey: ['admin', 'status'] });
+        },
+    });
+
+    const pauseMutation = useMutation({
+        mutationFn: (message: string) => pauseBooth(message),
+        onSuccess: () => {
+            queryClient.invalidateQueries({ queryKey: ['admin', 'status'] });
+        },
+    });
+
+    const resumeMutation = useMutation({
+        mutationFn: () => resumeBooth(),
         onSuccess: () => {
             queryClient.invalidateQueries({ queryKey: ['admin', 'status'] });
         },
@@ -74,7 +104,9 @@ export const AdminPanel = () => {
 
     const isLocked = statusQuery.data?.lock?.is_locked ?? false;
     const lockedPrizeId = statusQuery.data?.lock?.locked_prize_id ?? '';
+    const stationLocks = statusQuery.data?.lock?.station_locks ?? [];
     const stocks = statusQuery.data?.stocks ?? [];
+    const pause = statusQuery.data?.pause ?? null;
 
     // Show all prizes including "is_triggered" ones because we want to lock ANY prize
     // Or filter as needed. User said "List prizes".
@@ -198,6 +230,22 @@ export const AdminPanel = () => {
                                     </p>
                                 </div>
                             )}
+
+                            {/* Per-station locks, taken before the global lock */}
+                            {stationLocks.map((lock) => (
+                                <div key={lock.station_id} className="flex justify-between items-center bg-pangdip-custard/40 rounded-lg px-4 py-2">
+                                    <span className="text-pangdip-brown">
+                                        📱 {lock.station_id}: {lock.prize_id}
+                                    </span>
+                                    <button
+                                        onClick={() => unlockMutation.mutate(lock.station_id)}
+                                        className="text-sm text-red-500 hover:text-red-700 font-bold"
+                                        disabled={unlockMutation.isPending}
+                                    >
+                                        Unlock
+                                    </button>
+                                </div>
+                            ))}
                         </div>
                     </div>
 
@@ -228,6 +276,23 @@ export const AdminPanel = () => {
                         >
                             {resetMutation.isPending ? 'Resetting...' : '🔄 Reset All Stocks'}
                         </button>
+
+                        {/* Pause spinning while restocking */}
+                        <button
+                            onClick={() => {
+                                if (pause) {
+                                    resumeMutation.mutate();
+                                } else {
+                                    pauseMutation.mutate(window.prompt('ข้อความที่จะแสดงบนตู้ (ไม่บังคับ)') ?? '');
+                                }
+                            }}
+                            disabled={pauseMutation.isPending || resumeMutation.isPending}
+                            className="mt-3 w-full px-4 py-3 bg-gray-700 hover:bg-gray-800 
+                text-white font-display font-bold rounded-lg
+                transition-colors disabled:opacity-50"
+                        >
+                            {pause ? '▶️ Resume Booth' : '⏸️ Pause Booth'}
+                        </button>
                     </div>
                 </div>
 
@@ -238,7 +303,7 @@ export const AdminPanel = () => {
                             📋 Live Logs
                         </h2>
                         <span className="text-sm text-gray-500">
-                            Auto-refresh every 5s
+                            Live
                         </span>
                     </div>
 
@@ -1,16 +1,36 @@
 import { useState } from 'react';
+import { useQuery } from '@tanstack/react-query';
 import { SpinWheel } from '../components/SpinWheel';
 import { PrizeModal } from '../components/PrizeModal';
 import { useSpin } from '../hooks/useSpin';
-import type { SpinResult } from '../types';
+import { useKioskChannel } from '../hooks/useKioskChannel';
+import { getEvent, getWheel } from '../api/client';
+import { isAxiosError } from 'axios';
+import type { BoothPause, KioskCommand, SpinResult } from '../types';
 
 export const PublicGame = () => {
     const [showModal, setShowModal] = useState(false);
     const [prizeResult, setPrizeResult] = useState<SpinResult | null>(null);
     const [isSpinning, setIsSpinning] = useState(false);
-    const [result, setResult] = useState<string | null>(null);
+    const [targetAngle, setTargetAngle] = useState<number | null>(null);
+    const [isPaused, setIsPaused] = useState(false);
+    const [staffMessage, setStaffMessage] = useState('');
 
     const spinMutation = useSpin();
+    const wheelQuery = useQuery({
+        queryKey: ['wheel'],
+        queryFn: getWheel,
+        staleTime: Infinity,
+    });
+    const eventQuery = useQuery({
+        queryKey: ['event'],
+        queryFn: getEvent,
+        refetchInterval: 60000,
+    });
+    const isClosed = eventQuery.data?.open === false;
+    const opensAt = eventQuery.data?.opens_at
+        ? new Date(eventQuery.data.opens_at).toLocaleString('th-TH', { timeZone: eventQuery.data.timezone })
+        : null;
 
     const handleSpinClick = async () => {
         if (isSpinning) return;
@@ -22,7 +42,7 @@ export const PublicGame = () => {
 
             // 2. Store result and start spinning
             setPrizeResult(apiResult);
-            setResult(apiResult.result);
+            setTargetAngle(apiResult.target_angle);
             setIsSpinning(true);
 
             // 3. Wait for animation to complete
@@ -33,14 +53,42 @@ export const PublicGame = () => {
 
         } catch (error) {
             console.error('Spin error:', error);
+            if (isAxiosError(error) && error.response?.status === 503) {
+                const pause = error.response.data?.data as BoothPause | undefined;
+                alert(pause?.message || 'ขณะนี้ปิดให้บริการชั่วคราว');
+                return;
+            }
             alert('เกิดข้อผิดพลาด กรุณาลองใหม่อีกครั้ง');
         }
     };
 
+    // Commands from the admin panel; throwing rejects the command
+    useKioskChannel((command: KioskCommand) => {
+        switch (command.type) {
+            case 'pause':
+                setIsPaused(true);
+                break;
+            case 'resume':
+                setIsPaused(false);
+                break;
+            case 'message':
+                setStaffMessage(command.message ?? '');
+                break;
+            case 'spin':
+                if (isPaused) throw new Error('kiosk is paused');
+                if (isSpinning || spinMutation.isPending) throw new Error('kiosk is already spinning');
+                handleSpinClick();
+                break;
+            case 'lock':
+                // The server has already locked the prize; nothing to show
+                break;
+        }
+    });
+
     const handleCloseModal = () => {
         setShowModal(false);
         setPrizeResult(null);
-        setResult(null);
+        setTargetAngle(null);
     };
 
     return (
@@ -67,14 +115,27 @@ export const PublicGame = () => {
                     <SpinWheel
                         onSpin={handleSpinClick}
                         isSpinning={isSpinning}
-                        result={result}
-                        disabled={spinMutation.isPending}
+                        segments={wheelQuery.data?.segments ?? []}
+                        targetAngle={targetAngle}
+                        disabled={spinMutation.isPending || !wheelQuery.data || isPaused || isClosed}
                     />
                 </div>
 
+                {/* Message from staff */}
+                {staffMessage && (
+                    <button
+                        onClick={() => setStaffMessage('')}
+                        className="mb-4 px-4 py-2 rounded-xl bg-white shadow text-pangdip-brown font-body"
+                    >
+                        {staffMessage}
+                    </button>
+                )}
+
                 {/* Instructions */}
                 <p className="text-sm text-pangdip-brown/60 font-body mt-4">
-                    กดตรงกลางวงล้อเพื่อหมุนลุ้นรางวัล! 🎉
+                    {isClosed
+                        ? `บูธปิดแล้ว${opensAt ? ` เปิดอีกครั้ง ${opensAt}` : ''} 🌙`
+                        : isPaused ? 'พักการหมุนชั่วคราว ⏸️' : 'กดตรงกลางวงล้อเพื่อหมุนลุ้นรางวัล! 🎉'}
                 </p>
             </div>
 
@@ -0,0 +1,44 @@
+import { useEffect, useState } from 'react';
+import { displayFeedUrl } from '../api/client';
+import type { Announcement } from '../types';
+
+const MAX_WINNERS = 8;
+
+// Big-screen TV behind the booth showing recent winners
+export const WinnerDisplay = () => {
+    const [winners, setWinners] = useState<Announcement[]>([]);
+    const [isPaused, setIsPaused] = useState(false);
+
+    useEffect(() => {
+        const source = new EventSource(displayFeedUrl);
+        source.addEventListener('winner.announced', (event) => {
+            const winner = JSON.parse((event as MessageEvent).data) as Announcement;
+            setWinners((current) => [winner, ...current].slice(0, MAX_WINNERS));
+        });
+        source.addEventListener('display.paused', () => setIsPaused(true));
+        source.addEventListener('display.resumed', () => setIsPaused(false));
+        return () => source.close();
+    }, []);
+
+    return (
+        <div className="min-h-screen flex flex-col items-center px-8 py-12 bg-[#FFF5E6]">
+            <h1 className="text-6xl font-display font-bold text-pangdip-brown mb-12">
+                🎉 ผู้โชคดี 🎉
+            </h1>
+
+            {!isPaused && (
+                <ul className="w-full max-w-3xl space-y-4">
+                    {winners.map((winner, index) => (
+                        <li
+                            key={`${winner.won_at}-${index}`}
+                            className="flex items-center gap-6 bg-white rounded-2xl shadow-lg px-8 py-6 text-3xl font-body text-pangdip-brown"
+                        >
+                            <span className="text-5xl">{winner.icon}</span>
+                            <span>{winner.text}</span>
+                        </li>
+                    ))}
+                </ul>
+            )}
+        </div>
+    );
+};
@@ -7,6 +7,16 @@ export interface Prize {
     is_triggered: boolean;
 }
 
+// Display-only prize data from the public GET /api/prizes
+export interface PublicPrize {
+    id: string;
+    name: string;
+    names: Record<string, string>;
+    icon: string;
+    color: string;
+    segments: number[];
+}
+
 // Spin types
 export interface SpinRequest {
     instagram_id: string;
@@ -16,6 +26,26 @@ export interface SpinResult {
     result: string;
     prize_name: string;
     is_locked?: boolean;
+    segment_index: number;
+    target_angle: number;
+    voucher_code?: string;
+    claim_id?: string;
+    claim_token?: string;
+    practice?: boolean; // staff spin: not logged as a real spin, no stock used
+}
+
+// Wheel types (layout is owned by the server)
+export interface WheelSegment {
+    index: number;
+    prize_id: string;
+    label: string;
+    color: string;
+    start_angle: number;
+    end_angle: number;
+}
+
+export interface WheelLayout {
+    segments: WheelSegment[];
 }
 
 // Log types
@@ -25,7 +55,13 @@ export interface SpinLog {
     prize_won: string;
     prize_name: string;
     was_locked: boolean;
+    station_id?: string;
     timestamp: string;
+    voucher_code?: string;
+    voucher_status?: 'issued' | 'redeemed' | 'void';
+    gift_card_serial?: string;
+    campaign_id?: string;
+    event_id?: string;
 }
 
 // Lock types
@@ -34,9 +70,15 @@ export interface LockRequest {
     secret: string;
 }
 
+export interface StationLock {
+    station_id: string;
+    prize_id: string;
+}
+
 export interface LockStatus {
     is_locked: boolean;
     locked_prize_id?: string;
+    station_locks?: StationLock[];
 }
 
 // Stock types
@@ -54,9 +96,70 @@ export interface APIResponse<T = unknown> {
     data?: T;
 }
 
+// Whether the booth is open, from GET /api/event
+export interface EventStatus {
+    open: boolean;
+    override?: 'open' | 'closed';
+    opens_at?: string;
+    closes_at?: string;
+    timezone: string;
+    hours: { opens: string; closes: string }[];
+}
+
+// Everyone who spun with an Instagram handle, with their recent spins
+export interface PlayerProfile {
+    instagram_id: string;
+    first_seen_at: string;
+    last_seen_at: string;
+    total_spins: number;
+    wins: Record<string, number>;
+    notes: string;
+    history: SpinLog[];
+}
+
+// A fair the booth runs at; archived events keep their spin logs
+export interface BoothEvent {
+    id: string;
+    name: string;
+    started_at: string;
+    started_by?: string;
+    archived_at?: string;
+    total_spins: number;
+}
+
+// Set while spinning is paused; spins get a 503 with this as data
+export interface BoothPause {
+    message?: string;
+    resume_at?: string;
+    paused_by?: string;
+    paused_at: string;
+}
+
 export interface AdminStatus {
     lock: LockStatus;
     stocks: StockStatus[];
+    pause: BoothPause | null;
+}
+
+// Masked winner shown on the big-screen display
+export interface Announcement {
+    instagram_id?: string;
+    prize_id: string;
+    prize_name: string;
+    icon?: string;
+    text: string;
+    won_at: string;
+}
+
+// Remote control command pushed to the kiosk over its WebSocket
+export interface KioskCommand {
+    id: string;
+    station_id: string;
+    type: 'lock' | 'pause' | 'resume' | 'message' | 'spin';
+    prize_id?: string;
+    message?: string;
+    issued_by?: string;
+    issued_at: string;
 }
 
 // Component prop types